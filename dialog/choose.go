@@ -0,0 +1,85 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// Choose displays a modal dialog with a title, a prompt, and a list of items,
+// and returns the index of the item selected by the user.
+//
+// An error is returned if the dialog could not be displayed at all, or
+// ErrCancelled (with an index of -1) if it was dismissed without a choice.
+func Choose(title string, prompt string, items []string) (int, error) {
+    return Options{Title: title}.Choose(prompt, items)
+}
+
+// ChooseMany is like Choose, but the user can select any number of items
+// (including none), and returns their indexes in ascending order.
+//
+// An error is returned if the dialog could not be displayed at all, or
+// ErrCancelled if it was dismissed.
+func ChooseMany(title string, prompt string, items []string) ([]int, error) {
+    return Options{Title: title}.ChooseMany(prompt, items)
+}
+
+// Choose displays a modal dialog with a prompt and a list of items. See the
+// Choose function.
+func (o Options) Choose(prompt string, items []string) (int, error) {
+    return o.ChooseContext(context.Background(), prompt, items)
+}
+
+// ChooseContext is like Choose, but closes the dialog early if ctx is done.
+func (o Options) ChooseContext(ctx context.Context, prompt string, items []string) (int, error) {
+    var req = o.request(KindChoose, prompt)
+    req.Items = items
+
+    var response, _, err = show(ctx, req)
+    if err != nil { return -1, err }
+    if len(response.Choices) == 0 { return -1, ErrCancelled }
+    return response.Choices[0], nil
+}
+
+// ChooseMany displays a modal dialog with a prompt and a list of items, any
+// number of which can be selected. See the ChooseMany function.
+func (o Options) ChooseMany(prompt string, items []string) ([]int, error) {
+    return o.ChooseManyContext(context.Background(), prompt, items)
+}
+
+// ChooseManyContext is like ChooseMany, but closes the dialog early if ctx is
+// done.
+func (o Options) ChooseManyContext(ctx context.Context, prompt string, items []string) ([]int, error) {
+    var req = o.request(KindChooseMany, prompt)
+    req.Items = items
+
+    var response, _, err = show(ctx, req)
+    if err != nil { return nil, err }
+    return response.Choices, nil
+}
+
+// parseChoices parses a list of item numbers, counting from 1, separated by
+// spaces, commas or newlines, and returns them as sorted indexes counting
+// from 0 with duplicates removed. It returns false if any number is not in
+// the range 1 to n inclusive.
+func parseChoices(s string, n int) ([]int, bool) {
+    var fields = strings.FieldsFunc(s, func(r rune) bool {
+        return (r == ',') || (r == ' ') || (r == '\t') || (r == '\r') || (r == '\n')
+    })
+
+    var seen = make(map[int]bool)
+    var choices = make([]int, 0, len(fields))
+
+    for _, field := range fields {
+        var i, err = strconv.Atoi(field)
+        if (err != nil) || (i < 1) || (i > n) { return nil, false }
+        if seen[i] { continue }
+
+        seen[i] = true
+        choices = append(choices, i - 1)
+    }
+
+    sort.Ints(choices)
+    return choices, true
+}
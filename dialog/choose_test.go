@@ -0,0 +1,33 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestParseChoices(t *testing.T) {
+    var tests = []struct{
+        input string
+        n int
+        expected []int
+        ok bool
+    }{
+        {"",          3, []int{},        true},
+        {"1",         3, []int{0},       true},
+        {"3\n1\n",    3, []int{0, 2},    true},
+        {" 2, 3 1 ",  3, []int{0, 1, 2}, true},
+        {"2 2",       3, []int{1},       true},
+        {"0",         3, nil,            false},
+        {"4",         3, nil,            false},
+        {"1 x",       3, nil,            false},
+    }
+
+    for index, test := range tests {
+        var result, ok = parseChoices(test.input, test.n)
+        if ok != test.ok {
+            t.Errorf("Test %d: got ok=%t but wanted %t", index, ok, test.ok)
+        } else if ok && !reflect.DeepEqual(result, test.expected) {
+            t.Errorf("Test %d: got %v but wanted %v", index, result, test.expected)
+        }
+    }
+}
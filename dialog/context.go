@@ -0,0 +1,92 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "image/color"
+    "time"
+)
+
+// AlertContext is like Alert, but closes the dialog early if ctx is done.
+func AlertContext(ctx context.Context, message string, args...interface{}) error {
+    return Options{}.AlertContext(ctx, message, args...)
+}
+
+// ConfirmContext is like Confirm, but closes the dialog early if ctx is done.
+func ConfirmContext(ctx context.Context, message string, args...interface{}) (bool, error) {
+    return Options{}.ConfirmContext(ctx, message, args...)
+}
+
+// QuestionContext is like Question, but closes the dialog early if ctx is
+// done.
+func QuestionContext(ctx context.Context, message string, args...interface{}) (Answer, error) {
+    return Options{}.QuestionContext(ctx, message, args...)
+}
+
+// PromptContext is like Prompt, but closes the dialog early if ctx is done.
+func PromptContext(ctx context.Context, title string, message string, defaultValue string) (string, bool, error) {
+    return Options{Title: title}.PromptContext(ctx, message, defaultValue)
+}
+
+// PasswordContext is like Password, but closes the dialog early if ctx is
+// done.
+func PasswordContext(ctx context.Context, title string, message string) (string, bool, error) {
+    return Options{Title: title}.PasswordContext(ctx, message)
+}
+
+// ChooseContext is like Choose, but closes the dialog early if ctx is done.
+func ChooseContext(ctx context.Context, title string, prompt string, items []string) (int, error) {
+    return Options{Title: title}.ChooseContext(ctx, prompt, items)
+}
+
+// ChooseManyContext is like ChooseMany, but closes the dialog early if ctx is
+// done.
+func ChooseManyContext(ctx context.Context, title string, prompt string, items []string) ([]int, error) {
+    return Options{Title: title}.ChooseManyContext(ctx, prompt, items)
+}
+
+// OpenFileContext is like OpenFile, but closes the dialog early if ctx is
+// done.
+func OpenFileContext(ctx context.Context, title string, directory string, filters ...string) (string, bool, error) {
+    return Options{Title: title}.OpenFileContext(ctx, directory, filters...)
+}
+
+// OpenFilesContext is like OpenFiles, but closes the dialog early if ctx is
+// done.
+func OpenFilesContext(ctx context.Context, title string, directory string, filters ...string) ([]string, bool, error) {
+    return Options{Title: title}.OpenFilesContext(ctx, directory, filters...)
+}
+
+// SaveFileContext is like SaveFile, but closes the dialog early if ctx is
+// done.
+func SaveFileContext(ctx context.Context, title string, directory string, filename string, filters ...string) (string, bool, error) {
+    return Options{Title: title}.SaveFileContext(ctx, directory, filename, filters...)
+}
+
+// SelectFolderContext is like SelectFolder, but closes the dialog early if
+// ctx is done.
+func SelectFolderContext(ctx context.Context, title string, directory string) (string, bool, error) {
+    return Options{Title: title}.SelectFolderContext(ctx, directory)
+}
+
+// ProgressContext is like Progress, but closes the dialog when ctx is done.
+func ProgressContext(ctx context.Context, title string) (*ProgressDialog, error) {
+    return Options{Title: title}.ProgressContext(ctx, "")
+}
+
+// PickDateContext is like PickDate, but closes the dialog early if ctx is
+// done.
+func PickDateContext(ctx context.Context, title string, message string) (time.Time, bool, error) {
+    return Options{Title: title}.PickDateContext(ctx, message, time.Time{})
+}
+
+// PickColorContext is like PickColor, but closes the dialog early if ctx is
+// done.
+func PickColorContext(ctx context.Context, title string) (color.RGBA, bool, error) {
+    return Options{Title: title}.PickColorContext(ctx, color.RGBA{255, 255, 255, 255})
+}
+
+// PickNumberContext is like PickNumber, but closes the dialog early if ctx is
+// done.
+func PickNumberContext(ctx context.Context, title string, message string, min, max, step int) (int, bool, error) {
+    return Options{Title: title}.PickNumberContext(ctx, message, min, max, step, min)
+}
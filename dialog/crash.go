@@ -0,0 +1,102 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "fmt"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "runtime"
+    "runtime/debug"
+    "strings"
+    "time"
+)
+
+// CrashOptions customise the dialog displayed by RecoverAndReport.
+type CrashOptions struct {
+    // Options for the dialog. The Level is always LevelError, and the Details
+    // are always the crash report. Title defaults to "Error".
+    Options
+
+    // Message is displayed before the panic value, for example "Sorry, Foo
+    // has stopped working.". If empty, a generic message is used.
+    Message string
+
+    // Save, if true, offers to save the crash report to a file chosen by the
+    // user, for example to attach to a bug report.
+    Save bool
+
+    // ExitCode, if not zero, makes RecoverAndReport exit the program with
+    // this status after the dialog is closed, instead of panicking again.
+    ExitCode int
+}
+
+// exit is os.Exit, except in tests.
+var exit = os.Exit
+
+// RecoverAndReport recovers from a panic, displays the panic value in an
+// Error dialog with the stack trace as its details, and then either panics
+// again with the same value or, if options.ExitCode is not zero, exits the
+// program with that status. If there was no panic, it does nothing.
+//
+// It must be deferred directly, for example at the start of main:
+//
+//     func main() {
+//         defer dialog.RecoverAndReport(dialog.CrashOptions{Save: true})
+//         ...
+//     }
+//
+// It only recovers panics in the goroutine that deferred it. Note that
+// exiting the program skips any functions deferred before RecoverAndReport.
+func RecoverAndReport(options CrashOptions) {
+    var value = recover()
+    if value == nil { return }
+
+    options.show(value, crashReport(value, debug.Stack(), time.Now()))
+
+    if options.ExitCode != 0 { exit(options.ExitCode) }
+    panic(value)
+}
+
+// crashReport formats a panic value and a stack trace with enough
+// information about the program to be useful in a bug report.
+func crashReport(value interface{}, stack []byte, now time.Time) string {
+    var b strings.Builder
+    fmt.Fprintf(&b, "panic: %v\n\n", value)
+    fmt.Fprintf(&b, "program: %s\n", strings.Join(os.Args, " "))
+    fmt.Fprintf(&b, "time: %s\n", now.Format(time.RFC3339))
+    fmt.Fprintf(&b, "go: %s %s/%s\n\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+    b.Write(stack)
+    return b.String()
+}
+
+// show displays a crash report, and saves it if the user asks.
+func (o CrashOptions) show(value interface{}, report string) {
+    var options = o.Options
+    options.Level = LevelError
+    options.Details = report
+    if len(options.Title) == 0 { options.Title = "Error" }
+
+    var message = o.Message
+    if len(message) == 0 { message = "The program has stopped because of an unexpected error." }
+    message += "\n\n" + fmt.Sprint(value)
+
+    if !o.Save {
+        options.Alert("%s", message)
+        return
+    }
+
+    var save, _ = options.Confirm("%s\n\nSave a crash report?", message)
+    if !save { return }
+
+    // the other options, such as Details, don't make sense for a file dialog
+    var filename = fmt.Sprintf("%s-crash-%s.txt",
+        strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe"),
+        time.Now().Format("20060102-150405"))
+    var path, ok, _ = Options{Parent: o.Parent}.SaveFile("", filename, "Text files (*.txt)")
+    if !ok { return }
+
+    if err := ioutil.WriteFile(path, []byte(report), 0644); err != nil {
+        Options{Title: options.Title, Level: LevelError, Parent: o.Parent}.Alert(
+            "The crash report could not be saved.\n\n%v", err)
+    }
+}
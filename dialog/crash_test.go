@@ -0,0 +1,114 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "io/ioutil"
+    "path/filepath"
+    "strings"
+    "sync"
+    "testing"
+)
+
+// crashProvider records each dialog and answers each Confirm with Yes and
+// each SaveFile with path.
+type crashProvider struct {
+    path string
+
+    mutex sync.Mutex
+    requests []Request
+}
+
+func (p *crashProvider) Name() string { return "crash" }
+
+func (p *crashProvider) Show(ctx context.Context, req *Request) (Response, error) {
+    p.mutex.Lock()
+    defer p.mutex.Unlock()
+
+    p.requests = append(p.requests, *req)
+    switch req.Kind {
+        case KindConfirm:  return Response{Answer: Yes}, nil
+        case KindSaveFile: return Response{Paths: []string{p.path}}, nil
+        default:           return Response{}, nil
+    }
+}
+
+func TestRecoverAndReport(t *testing.T) {
+    useProviders(t)
+
+    var savedExit = exit
+    defer func() { exit = savedExit }()
+
+    var path = filepath.Join(t.TempDir(), "report.txt")
+
+    var tests = []struct{
+        options CrashOptions
+        kinds string
+        exitCode int
+    }{
+        {CrashOptions{},                               "Alert",            0},
+        {CrashOptions{ExitCode: 3},                    "Alert",            3},
+        {CrashOptions{Save: true, Message: "Sorry."},  "Confirm SaveFile", 0},
+    }
+
+    for index, test := range tests {
+        var p = &crashProvider{path: path}
+        SetProviders(p)
+
+        var exitCode = 0
+        exit = func(code int) { exitCode = code }
+
+        var repanic = func() (value interface{}) {
+            defer func() { value = recover() }()
+            func() {
+                defer RecoverAndReport(test.options)
+                panic("oh no")
+            }()
+            return nil
+        }()
+
+        // exit returns in this test, so there is always a panic afterwards
+        if repanic != "oh no" {
+            t.Errorf("Test %d: got panic %v but wanted %q", index, repanic, "oh no")
+        }
+        if exitCode != test.exitCode {
+            t.Errorf("Test %d: got exit code %d but wanted %d", index, exitCode, test.exitCode)
+        }
+
+        var kinds = make([]string, 0)
+        for _, req := range p.requests { kinds = append(kinds, req.Kind.String()) }
+        if result := strings.Join(kinds, " "); result != test.kinds {
+            t.Errorf("Test %d: got dialogs %q but wanted %q", index, result, test.kinds)
+            continue
+        }
+
+        var req = p.requests[0]
+        if (req.Level != LevelError) || (req.Title != "Error") ||
+            !strings.Contains(req.Message, "\n\noh no") ||
+            !strings.HasPrefix(req.Details, "panic: oh no\n") {
+            t.Errorf("Test %d: unexpected request %+v", index, req)
+        }
+        if (len(test.options.Message) > 0) && !strings.HasPrefix(req.Message, test.options.Message) {
+            t.Errorf("Test %d: got message %q", index, req.Message)
+        }
+    }
+
+    var report, err = ioutil.ReadFile(path)
+    if err != nil {
+        t.Errorf("report was not saved: %v", err)
+    } else if !strings.Contains(string(report), "RecoverAndReport") {
+        t.Errorf("report has no stack trace: %s", report)
+    }
+}
+
+func TestRecoverAndReportNoPanic(t *testing.T) {
+    var p = &crashProvider{}
+    useProviders(t, p)
+
+    func() {
+        defer RecoverAndReport(CrashOptions{})
+    }()
+
+    if len(p.requests) != 0 {
+        t.Errorf("unexpected dialogs %+v", p.requests)
+    }
+}
@@ -0,0 +1,689 @@
+// +build linux
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+// This is a minimal D-Bus client: just enough to call methods on the session
+// bus and receive signals. Values are encoded and decoded according to a D-Bus
+// signature:
+//
+//     y (byte), b (bool), n (int16), q (uint16), i (int32), u (uint32),
+//     x (int64), t (uint64), d (float64), s, o and g (string),
+//     v (dbusVariant), and arrays, structs and dict entries ([]interface{}).
+//
+// As special cases, "ay" is a []byte and "as" is a []string.
+
+import (
+    "bufio"
+    "context"
+    "encoding/binary"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io"
+    "math"
+    "net"
+    "os"
+    "strconv"
+    "strings"
+    "sync"
+)
+
+// message types
+const (
+    dbusMethodCall   = 1
+    dbusMethodReturn = 2
+    dbusError        = 3
+    dbusSignal       = 4
+)
+
+// header fields
+const (
+    dbusFieldPath        = 1
+    dbusFieldInterface   = 2
+    dbusFieldMember      = 3
+    dbusFieldErrorName   = 4
+    dbusFieldReplySerial = 5
+    dbusFieldDestination = 6
+    dbusFieldSender      = 7
+    dbusFieldSignature   = 8
+)
+
+const dbusMaxMessage = 128 * 1024 * 1024
+
+var errDBusClosed = errors.New("dbus: connection closed")
+
+// dbusVariant is a value of any type, with its signature.
+type dbusVariant struct {
+    signature string
+    value interface{}
+}
+
+type dbusMessage struct {
+    kind byte
+    flags byte
+    serial uint32
+    replySerial uint32
+    path, iface, member, errorName, destination, sender string
+    signature string
+    body []interface{}
+}
+
+// dbusEntry returns an entry for a dict of type a{sv}.
+func dbusEntry(key string, signature string, value interface{}) []interface{} {
+    return []interface{}{key, dbusVariant{signature, value}}
+}
+
+// dbusLookup finds the value of an entry in a dict of type a{sv}.
+func dbusLookup(dict interface{}, key string) (interface{}, bool) {
+    var entries, _ = dict.([]interface{})
+    for _, e := range entries {
+        var entry, ok = e.([]interface{})
+        if !ok || (len(entry) != 2) || (entry[0] != key) { continue }
+        if v, ok := entry[1].(dbusVariant); ok { return v.value, true }
+    }
+    return nil, false
+}
+
+// dbusNext returns the length of the first complete type in a signature.
+func dbusNext(signature string) (int, error) {
+    if len(signature) == 0 { return 0, errors.New("dbus: incomplete signature") }
+
+    switch signature[0] {
+        case 'a':
+            var n, err = dbusNext(signature[1:])
+            return n + 1, err
+
+        case '(', '{':
+            var end = byte(')')
+            if signature[0] == '{' { end = '}' }
+
+            var i = 1
+            for (i < len(signature)) && (signature[i] != end) {
+                var n, err = dbusNext(signature[i:])
+                if err != nil { return 0, err }
+                i += n
+            }
+            if i >= len(signature) { return 0, errors.New("dbus: unbalanced signature") }
+            if i == 1 { return 0, errors.New("dbus: empty struct or dict entry in signature") }
+            return i + 1, nil
+
+        case 'y', 'b', 'n', 'q', 'i', 'u', 'x', 't', 'd', 's', 'o', 'g', 'v':
+            return 1, nil
+
+        default:
+            return 0, fmt.Errorf("dbus: unsupported type %q in signature", signature[0])
+    }
+}
+
+// dbusSplit splits a signature into complete types.
+func dbusSplit(signature string) ([]string, error) {
+    var types []string
+    for len(signature) > 0 {
+        var n, err = dbusNext(signature)
+        if err != nil { return nil, err }
+        types = append(types, signature[0:n])
+        signature = signature[n:]
+    }
+    return types, nil
+}
+
+// dbusAlignment returns the alignment of a type, in bytes.
+func dbusAlignment(signature string) int {
+    switch signature[0] {
+        case 'y', 'g', 'v':           return 1
+        case 'n', 'q':                return 2
+        case 'x', 't', 'd', '(', '{': return 8
+        default:                      return 4
+    }
+}
+
+// dbusEncoder encodes values in little-endian order. Alignment is relative
+// to the start of buf, so a message body must be encoded separately from the
+// header.
+type dbusEncoder struct {
+    buf []byte
+}
+
+func (e *dbusEncoder) align(n int) {
+    for len(e.buf) % n != 0 { e.buf = append(e.buf, 0) }
+}
+
+func (e *dbusEncoder) uint32(v uint32) {
+    e.align(4)
+    var b [4]byte
+    binary.LittleEndian.PutUint32(b[:], v)
+    e.buf = append(e.buf, b[:]...)
+}
+
+func (e *dbusEncoder) uint64(v uint64) {
+    e.align(8)
+    var b [8]byte
+    binary.LittleEndian.PutUint64(b[:], v)
+    e.buf = append(e.buf, b[:]...)
+}
+
+func (e *dbusEncoder) encodeAll(signature string, values []interface{}) error {
+    var types, err = dbusSplit(signature)
+    if err != nil { return err }
+    if len(types) != len(values) {
+        return fmt.Errorf("dbus: signature %q does not match %d values", signature, len(values))
+    }
+
+    for i, t := range types {
+        if err := e.encode(t, values[i]); err != nil { return err }
+    }
+    return nil
+}
+
+func (e *dbusEncoder) encode(signature string, value interface{}) error {
+    var wrongType = fmt.Errorf("dbus: can't encode %T as %q", value, signature)
+
+    switch signature[0] {
+        case 'y':
+            var v, ok = value.(byte)
+            if !ok { return wrongType }
+            e.buf = append(e.buf, v)
+
+        case 'b':
+            var v, ok = value.(bool)
+            if !ok { return wrongType }
+            if v { e.uint32(1) } else { e.uint32(0) }
+
+        case 'n', 'q':
+            var v uint16
+            switch x := value.(type) {
+                case int16:  v = uint16(x)
+                case uint16: v = x
+                default:     return wrongType
+            }
+            e.align(2)
+            e.buf = append(e.buf, byte(v), byte(v >> 8))
+
+        case 'i', 'u':
+            switch x := value.(type) {
+                case int32:  e.uint32(uint32(x))
+                case uint32: e.uint32(x)
+                default:     return wrongType
+            }
+
+        case 'x', 't', 'd':
+            switch x := value.(type) {
+                case int64:   e.uint64(uint64(x))
+                case uint64:  e.uint64(x)
+                case float64: e.uint64(math.Float64bits(x))
+                default:      return wrongType
+            }
+
+        case 's', 'o':
+            var v, ok = value.(string)
+            if !ok { return wrongType }
+            e.uint32(uint32(len(v)))
+            e.buf = append(append(e.buf, v...), 0)
+
+        case 'g':
+            var v, ok = value.(string)
+            if !ok || (len(v) > 255) { return wrongType }
+            e.buf = append(append(append(e.buf, byte(len(v))), v...), 0)
+
+        case 'v':
+            var v, ok = value.(dbusVariant)
+            if !ok { return wrongType }
+            if err := e.encode("g", v.signature); err != nil { return err }
+            return e.encode(v.signature, v.value)
+
+        case 'a':
+            var elements []interface{}
+            switch x := value.(type) {
+                case []interface{}: elements = x
+                case []byte:        for _, b := range x { elements = append(elements, b) }
+                case []string:      for _, s := range x { elements = append(elements, s) }
+                default:            return wrongType
+            }
+
+            var element = signature[1:]
+            e.uint32(0) // length, filled in later
+            var lengthAt = len(e.buf) - 4
+            e.align(dbusAlignment(element))
+            var start = len(e.buf)
+
+            for _, x := range elements {
+                if err := e.encode(element, x); err != nil { return err }
+            }
+
+            binary.LittleEndian.PutUint32(e.buf[lengthAt:], uint32(len(e.buf) - start))
+
+        case '(', '{':
+            var fields, ok = value.([]interface{})
+            if !ok { return wrongType }
+            e.align(8)
+            return e.encodeAll(signature[1:len(signature)-1], fields)
+    }
+
+    return nil
+}
+
+// dbusDecoder decodes values. Alignment is relative to the start of buf.
+type dbusDecoder struct {
+    buf []byte
+    pos int
+    order binary.ByteOrder
+    depth int // containers entered so far
+}
+
+// dbusMaxDepth is the most arrays, structs, dict entries and variants that
+// can be nested inside each other, as in the D-Bus specification. Variants
+// can nest without limit in a signature, so without this, a message could
+// overflow the stack.
+const dbusMaxDepth = 64
+
+var errDBusShort = errors.New("dbus: message too short")
+var errDBusDepth = errors.New("dbus: values nested too deeply")
+
+func (d *dbusDecoder) align(n int) error {
+    d.pos = (d.pos + n - 1) / n * n
+    if d.pos > len(d.buf) { return errDBusShort }
+    return nil
+}
+
+func (d *dbusDecoder) read(n int) ([]byte, error) {
+    if d.pos + n > len(d.buf) { return nil, errDBusShort }
+    var b = d.buf[d.pos:d.pos+n]
+    d.pos += n
+    return b, nil
+}
+
+func (d *dbusDecoder) uint32() (uint32, error) {
+    if err := d.align(4); err != nil { return 0, err }
+    var b, err = d.read(4)
+    if err != nil { return 0, err }
+    return d.order.Uint32(b), nil
+}
+
+func (d *dbusDecoder) decodeAll(signature string) ([]interface{}, error) {
+    var types, err = dbusSplit(signature)
+    if err != nil { return nil, err }
+
+    var values = make([]interface{}, 0, len(types))
+    for _, t := range types {
+        var v, err = d.decode(t)
+        if err != nil { return nil, err }
+        values = append(values, v)
+    }
+    return values, nil
+}
+
+func (d *dbusDecoder) decode(signature string) (interface{}, error) {
+    switch signature[0] {
+        case 'a', '(', '{', 'v':
+            if d.depth >= dbusMaxDepth { return nil, errDBusDepth }
+            d.depth++
+            defer func() { d.depth-- }()
+    }
+
+    switch signature[0] {
+        case 'y':
+            var b, err = d.read(1)
+            if err != nil { return nil, err }
+            return b[0], nil
+
+        case 'b':
+            var v, err = d.uint32()
+            return v != 0, err
+
+        case 'n', 'q':
+            if err := d.align(2); err != nil { return nil, err }
+            var b, err = d.read(2)
+            if err != nil { return nil, err }
+            if signature[0] == 'n' { return int16(d.order.Uint16(b)), nil }
+            return d.order.Uint16(b), nil
+
+        case 'i':
+            var v, err = d.uint32()
+            return int32(v), err
+
+        case 'u':
+            return d.uint32()
+
+        case 'x', 't', 'd':
+            if err := d.align(8); err != nil { return nil, err }
+            var b, err = d.read(8)
+            if err != nil { return nil, err }
+            var v = d.order.Uint64(b)
+            switch signature[0] {
+                case 'x': return int64(v), nil
+                case 'd': return math.Float64frombits(v), nil
+                default:  return v, nil
+            }
+
+        case 's', 'o':
+            var length, err = d.uint32()
+            if err != nil { return nil, err }
+            b, err := d.read(int(length) + 1)
+            if err != nil { return nil, err }
+            return string(b[0:length]), nil
+
+        case 'g':
+            var length, err = d.read(1)
+            if err != nil { return nil, err }
+            b, err := d.read(int(length[0]) + 1)
+            if err != nil { return nil, err }
+            return string(b[0:length[0]]), nil
+
+        case 'v':
+            var s, err = d.decode("g")
+            if err != nil { return nil, err }
+            var signature = s.(string)
+            if n, err := dbusNext(signature); (err != nil) || (n != len(signature)) {
+                return nil, fmt.Errorf("dbus: invalid variant signature %q", signature)
+            }
+            v, err := d.decode(signature)
+            return dbusVariant{signature, v}, err
+
+        case 'a':
+            var length, err = d.uint32()
+            if err != nil { return nil, err }
+
+            var element = signature[1:]
+            if err := d.align(dbusAlignment(element)); err != nil { return nil, err }
+            var end = d.pos + int(length)
+            if end > len(d.buf) { return nil, errDBusShort }
+
+            var values = make([]interface{}, 0)
+            for d.pos < end {
+                // an element that takes up no space would repeat forever
+                var start = d.pos
+                var v, err = d.decode(element)
+                if err != nil { return nil, err }
+                if d.pos == start { return nil, fmt.Errorf("dbus: empty array element %q", element) }
+                values = append(values, v)
+            }
+
+            switch element {
+                case "y":
+                    var bytes = make([]byte, len(values))
+                    for i, v := range values { bytes[i] = v.(byte) }
+                    return bytes, nil
+                case "s":
+                    var strings = make([]string, len(values))
+                    for i, v := range values { strings[i] = v.(string) }
+                    return strings, nil
+                default:
+                    return values, nil
+            }
+
+        case '(', '{':
+            if err := d.align(8); err != nil { return nil, err }
+            return d.decodeAll(signature[1:len(signature)-1])
+    }
+
+    return nil, fmt.Errorf("dbus: unsupported type %q", signature)
+}
+
+// encode returns the wire format of a message.
+func (m *dbusMessage) encode() ([]byte, error) {
+    var body dbusEncoder
+    if err := body.encodeAll(m.signature, m.body); err != nil { return nil, err }
+
+    var fields []interface{}
+    var field = func(code byte, signature string, value interface{}) {
+        fields = append(fields, []interface{}{code, dbusVariant{signature, value}})
+    }
+    if len(m.path) > 0        { field(dbusFieldPath, "o", m.path) }
+    if len(m.iface) > 0       { field(dbusFieldInterface, "s", m.iface) }
+    if len(m.member) > 0      { field(dbusFieldMember, "s", m.member) }
+    if len(m.errorName) > 0   { field(dbusFieldErrorName, "s", m.errorName) }
+    if m.replySerial != 0     { field(dbusFieldReplySerial, "u", m.replySerial) }
+    if len(m.destination) > 0 { field(dbusFieldDestination, "s", m.destination) }
+    if len(m.signature) > 0   { field(dbusFieldSignature, "g", m.signature) }
+
+    var header = dbusEncoder{buf: []byte{'l', m.kind, m.flags, 1}}
+    header.uint32(uint32(len(body.buf)))
+    header.uint32(m.serial)
+    if err := header.encode("a(yv)", fields); err != nil { return nil, err }
+    header.align(8)
+
+    return append(header.buf, body.buf...), nil
+}
+
+// readDBusMessage reads and decodes a message.
+func readDBusMessage(r io.Reader) (*dbusMessage, error) {
+    var fixed = make([]byte, 16)
+    if _, err := io.ReadFull(r, fixed); err != nil { return nil, err }
+
+    var order binary.ByteOrder
+    switch fixed[0] {
+        case 'l': order = binary.LittleEndian
+        case 'B': order = binary.BigEndian
+        default:  return nil, fmt.Errorf("dbus: invalid byte order %q", fixed[0])
+    }
+
+    var bodyLength = int(order.Uint32(fixed[4:]))
+    var fieldsLength = int(order.Uint32(fixed[12:]))
+    var headerLength = (16 + fieldsLength + 7) / 8 * 8
+    if (bodyLength > dbusMaxMessage) || (fieldsLength > dbusMaxMessage) {
+        return nil, errors.New("dbus: message too long")
+    }
+
+    var buf = make([]byte, headerLength + bodyLength)
+    copy(buf, fixed)
+    if _, err := io.ReadFull(r, buf[16:]); err != nil { return nil, err }
+
+    var m = &dbusMessage{
+        kind: fixed[1],
+        flags: fixed[2],
+        serial: order.Uint32(fixed[8:]),
+    }
+
+    var header = dbusDecoder{buf: buf[0:headerLength], pos: 12, order: order}
+    var fields, err = header.decode("a(yv)")
+    if err != nil { return nil, err }
+
+    for _, f := range fields.([]interface{}) {
+        var field = f.([]interface{})
+        var value = field[1].(dbusVariant).value
+        switch field[0].(byte) {
+            case dbusFieldPath:        m.path, _ = value.(string)
+            case dbusFieldInterface:   m.iface, _ = value.(string)
+            case dbusFieldMember:      m.member, _ = value.(string)
+            case dbusFieldErrorName:   m.errorName, _ = value.(string)
+            case dbusFieldReplySerial: m.replySerial, _ = value.(uint32)
+            case dbusFieldDestination: m.destination, _ = value.(string)
+            case dbusFieldSender:      m.sender, _ = value.(string)
+            case dbusFieldSignature:   m.signature, _ = value.(string)
+        }
+    }
+
+    var body = dbusDecoder{buf: buf[headerLength:], order: order}
+    m.body, err = body.decodeAll(m.signature)
+    return m, err
+}
+
+// dbusConn is a connection to a message bus.
+type dbusConn struct {
+    conn net.Conn
+    name string // unique name assigned by the bus
+
+    writeMutex sync.Mutex
+
+    mutex sync.Mutex
+    serial uint32
+    replies map[uint32]chan *dbusMessage
+    handler func(m *dbusMessage)
+
+    err error // why the connection closed
+    closed chan struct{}
+}
+
+// dialDBus connects to a message bus at an address such as the value of
+// $DBUS_SESSION_BUS_ADDRESS, e.g. "unix:path=/run/user/1000/bus".
+func dialDBus(address string) (*dbusConn, error) {
+    var conn net.Conn
+    var err = errors.New("dbus: no supported address")
+
+    for _, a := range strings.Split(address, ";") {
+        if !strings.HasPrefix(a, "unix:") { continue }
+
+        for _, kv := range strings.Split(a[5:], ",") {
+            switch {
+                case strings.HasPrefix(kv, "path="):
+                    conn, err = net.Dial("unix", kv[5:])
+                case strings.HasPrefix(kv, "abstract="):
+                    conn, err = net.Dial("unix", "@"+kv[9:])
+            }
+        }
+        if conn != nil { break }
+    }
+    if conn == nil { return nil, err }
+
+    var reader = bufio.NewReader(conn)
+    if err := dbusAuth(conn, reader); err != nil {
+        conn.Close()
+        return nil, err
+    }
+
+    var c = &dbusConn{
+        conn: conn,
+        replies: make(map[uint32]chan *dbusMessage),
+        closed: make(chan struct{}),
+    }
+    go c.readLoop(reader)
+
+    var reply, herr = c.call(context.Background(), "org.freedesktop.DBus", "/org/freedesktop/DBus",
+        "org.freedesktop.DBus", "Hello", "")
+    if herr != nil {
+        c.Close()
+        return nil, herr
+    }
+    if len(reply) == 0 {
+        c.Close()
+        return nil, errors.New("dbus: empty reply to Hello")
+    }
+    c.name, _ = reply[0].(string)
+
+    return c, nil
+}
+
+// dbusAuth authenticates as the current user.
+func dbusAuth(w io.Writer, r *bufio.Reader) error {
+    var uid = hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+    if _, err := fmt.Fprintf(w, "\x00AUTH EXTERNAL %s\r\n", uid); err != nil { return err }
+
+    var line, err = r.ReadString('\n')
+    if err != nil { return err }
+    if !strings.HasPrefix(line, "OK ") {
+        return fmt.Errorf("dbus: authentication failed: %s", strings.TrimSpace(line))
+    }
+
+    _, err = io.WriteString(w, "BEGIN\r\n")
+    return err
+}
+
+func (c *dbusConn) Close() error {
+    return c.conn.Close()
+}
+
+func (c *dbusConn) readLoop(r io.Reader) {
+    for {
+        var m, err = readDBusMessage(r)
+        if err != nil {
+            c.mutex.Lock()
+            c.err = err
+            c.mutex.Unlock()
+            close(c.closed)
+            return
+        }
+
+        c.mutex.Lock()
+        var reply = c.replies[m.replySerial]
+        delete(c.replies, m.replySerial)
+        var handler = c.handler
+        c.mutex.Unlock()
+
+        switch m.kind {
+            case dbusMethodReturn, dbusError:
+                if reply != nil { reply <- m }
+            case dbusSignal, dbusMethodCall:
+                if handler != nil { handler(m) }
+        }
+    }
+}
+
+// handle sets a function that is called, in order, with each signal or
+// method call received.
+func (c *dbusConn) handle(f func(m *dbusMessage)) {
+    c.mutex.Lock()
+    defer c.mutex.Unlock()
+
+    c.handler = f
+}
+
+// send sends a message, setting its serial number.
+func (c *dbusConn) send(m *dbusMessage) error {
+    c.mutex.Lock()
+    c.serial++
+    m.serial = c.serial
+    c.mutex.Unlock()
+
+    var buf, err = m.encode()
+    if err != nil { return err }
+
+    c.writeMutex.Lock()
+    defer c.writeMutex.Unlock()
+
+    _, err = c.conn.Write(buf)
+    return err
+}
+
+// call calls a method and waits for its reply.
+func (c *dbusConn) call(ctx context.Context, destination, path, iface, member, signature string, args ...interface{}) ([]interface{}, error) {
+    var m = &dbusMessage{
+        kind: dbusMethodCall,
+        destination: destination,
+        path: path,
+        iface: iface,
+        member: member,
+        signature: signature,
+        body: args,
+    }
+
+    var reply = make(chan *dbusMessage, 1)
+
+    // hold the lock so that the reply can't arrive before it is expected
+    c.mutex.Lock()
+    c.serial++
+    m.serial = c.serial
+    c.replies[m.serial] = reply
+    c.mutex.Unlock()
+
+    var buf, err = m.encode()
+    if err == nil {
+        c.writeMutex.Lock()
+        _, err = c.conn.Write(buf)
+        c.writeMutex.Unlock()
+    }
+    if err != nil {
+        c.mutex.Lock()
+        delete(c.replies, m.serial)
+        c.mutex.Unlock()
+        return nil, err
+    }
+
+    select {
+        case r := <-reply:
+            if r.kind == dbusError {
+                var message string
+                if len(r.body) > 0 { message, _ = r.body[0].(string) }
+                return nil, fmt.Errorf("%s: %s", r.errorName, message)
+            }
+            return r.body, nil
+
+        case <-c.closed:
+            return nil, errDBusClosed
+
+        case <-ctx.Done():
+            c.mutex.Lock()
+            delete(c.replies, m.serial)
+            c.mutex.Unlock()
+            return nil, ctx.Err()
+    }
+}
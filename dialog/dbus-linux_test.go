@@ -0,0 +1,137 @@
+// +build linux
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "bytes"
+    "encoding/binary"
+    "reflect"
+    "testing"
+)
+
+func TestDBusMessage(t *testing.T) {
+    var tests = []struct{
+        signature string
+        body []interface{}
+    }{
+        {"", []interface{}{}},
+        {"s", []interface{}{"hello"}},
+        {"ybnqiuxtd", []interface{}{byte(1), true, int16(-2), uint16(3), int32(-4), uint32(5), int64(-6), uint64(7), 8.5}},
+        {"ogv", []interface{}{"/a/b", "a{sv}", dbusVariant{"as", []string{"x", "y"}}}},
+        {"ayas", []interface{}{[]byte("abc\x00"), []string{}}},
+        {"ya(yt)", []interface{}{byte(1), []interface{}{[]interface{}{byte(2), uint64(3)}}}},
+        {"sa{sv}", []interface{}{"id", []interface{}{
+            dbusEntry("a", "b", true),
+            dbusEntry("b", "a(sa(us))", []interface{}{
+                []interface{}{"Images", []interface{}{
+                    []interface{}{uint32(0), "*.png"},
+                    []interface{}{uint32(0), "*.jpg"},
+                }},
+            }),
+        }}},
+    }
+
+    for index, test := range tests {
+        var m = &dbusMessage{
+            kind: dbusMethodCall,
+            serial: 42,
+            path: "/org/example",
+            iface: "org.example.Test",
+            member: "Test",
+            destination: "org.example",
+            signature: test.signature,
+            body: test.body,
+        }
+
+        var buf, err = m.encode()
+        if err != nil {
+            t.Errorf("Test %d: unexpected encode error: %v", index, err)
+            continue
+        }
+
+        result, err := readDBusMessage(bytes.NewReader(buf))
+        if err != nil {
+            t.Errorf("Test %d: unexpected decode error: %v", index, err)
+        } else if !reflect.DeepEqual(result, m) {
+            t.Errorf("Test %d: got %+v but wanted %+v", index, result, m)
+        }
+    }
+}
+
+func TestDBusEncodeErrors(t *testing.T) {
+    var tests = []struct{
+        signature string
+        body []interface{}
+    }{
+        {"s", []interface{}{}},
+        {"s", []interface{}{1}},
+        {"a{sv}", []interface{}{[]interface{}{[]interface{}{"key", "not a variant"}}}},
+        {"(s", []interface{}{[]interface{}{"x"}}},
+        {"h", []interface{}{int32(0)}},
+    }
+
+    for index, test := range tests {
+        var m = &dbusMessage{kind: dbusSignal, signature: test.signature, body: test.body}
+        if _, err := m.encode(); err == nil {
+            t.Errorf("Test %d: expected an error", index)
+        }
+    }
+}
+
+func TestDBusDecodeDepth(t *testing.T) {
+    // a variant that contains a variant, and so on, without end
+    var buf = bytes.Repeat([]byte{1, 'v', 0}, dbusMaxDepth + 1)
+    var d = &dbusDecoder{buf: buf, order: binary.LittleEndian}
+    if _, err := d.decode("v"); err != errDBusDepth {
+        t.Errorf("got %v but wanted %v", err, errDBusDepth)
+    }
+
+    // but up to the limit is fine: the innermost variant holds a byte
+    buf = append(bytes.Repeat([]byte{1, 'v', 0}, dbusMaxDepth - 1), 1, 'y', 0, 42)
+    d = &dbusDecoder{buf: buf, order: binary.LittleEndian}
+    if _, err := d.decode("v"); err != nil {
+        t.Errorf("unexpected error: %v", err)
+    }
+}
+
+func TestDBusDecodeEmpty(t *testing.T) {
+    if _, err := dbusSplit("a()"); err == nil {
+        t.Errorf("expected an error for an empty struct")
+    }
+    if _, err := dbusSplit("a{}"); err == nil {
+        t.Errorf("expected an error for an empty dict entry")
+    }
+
+    // an array of 8 bytes of empty structs, which would never end
+    var buf = []byte{8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+    var d = &dbusDecoder{buf: buf, order: binary.LittleEndian}
+    if _, err := d.decode("a()"); err == nil {
+        t.Errorf("expected an error for an array of empty structs")
+    }
+}
+
+// reply and emit are only needed to provide a service, which is useful for
+// testing.
+
+// reply replies to a method call.
+func (c *dbusConn) reply(call *dbusMessage, signature string, args ...interface{}) error {
+    return c.send(&dbusMessage{
+        kind: dbusMethodReturn,
+        replySerial: call.serial,
+        destination: call.sender,
+        signature: signature,
+        body: args,
+    })
+}
+
+// emit broadcasts a signal.
+func (c *dbusConn) emit(path, iface, member, signature string, args ...interface{}) error {
+    return c.send(&dbusMessage{
+        kind: dbusSignal,
+        path: path,
+        iface: iface,
+        member: member,
+        signature: signature,
+        body: args,
+    })
+}
@@ -1,12 +1,126 @@
 package dialog
 
+import (
+    "context"
+    "fmt"
+)
+
+// Answer is the button pressed by the user in response to a Question dialog.
+type Answer int
+
+const (
+    Yes Answer = iota + 1
+    No
+    Cancel
+)
+
+// String returns a human-readable label for the answer e.g. "Yes".
+func (a Answer) String() string {
+    switch a {
+        case Yes:    return "Yes"
+        case No:     return "No"
+        case Cancel: return "Cancel"
+        default:     return "Answer(?)"
+    }
+}
+
+// Level is the severity of a message, which determines the icon displayed
+// alongside it.
+type Level int
+
+const (
+    LevelDefault Level = iota // the usual look of an Alert on each platform
+    LevelInfo
+    LevelWarning
+    LevelError
+)
+
+// String returns a human-readable label for the level e.g. "Warning".
+func (l Level) String() string {
+    switch l {
+        case LevelDefault: return "Default"
+        case LevelInfo:    return "Info"
+        case LevelWarning: return "Warning"
+        case LevelError:   return "Error"
+        default:           return "Level(?)"
+    }
+}
+
+// format applies printf-style formatting to message only if there are
+// arguments, so that a message like "100%" is safe to display as-is.
+func format(message string, args []interface{}) string {
+    if len(args) == 0 { return message }
+    return fmt.Sprintf(message, args...)
+}
+
 // Alert displays a modal message box with message. The message string can
 // be a printf-style format string for an optional sequence of additional
 // arguments of any type.
 func Alert(message string, args...interface{}) {
-    if len(args) == 0 {
-        platformAlert("Alert", "%s", message)
-    } else {
-        platformAlert("Alert", message, args...)
-    }
+    AlertE(message, args...)
+}
+
+// AlertE is like Alert, but reports which provider displayed the message
+// and why any providers tried before it failed.
+//
+// If no provider was able to display the message, the returned error is a
+// *ProviderError that wraps ErrNoProvider.
+func AlertE(message string, args...interface{}) (Report, error) {
+    return Options{}.alert(context.Background(), format(message, args))
+}
+
+// Info displays a modal message box with message and an information icon.
+// The message string can be a printf-style format string for an optional
+// sequence of additional arguments of any type.
+//
+// If no provider was able to display the message, the returned error is a
+// *ProviderError that wraps ErrNoProvider.
+func Info(message string, args...interface{}) error {
+    return Options{Level: LevelInfo}.Alert(message, args...)
+}
+
+// Warning is like Info, but displays a warning icon.
+func Warning(message string, args...interface{}) error {
+    return Options{Level: LevelWarning}.Alert(message, args...)
+}
+
+// Error is like Info, but displays an error icon.
+func Error(message string, args...interface{}) error {
+    return Options{Level: LevelError}.Alert(message, args...)
+}
+
+// Confirm displays a modal message box with message and "Yes" and "No"
+// buttons, and returns true iff the user pressed "Yes". The message string
+// can be a printf-style format string for an optional sequence of additional
+// arguments of any type.
+//
+// An error is returned if the dialog could not be displayed at all, or
+// ErrCancelled if it was dismissed without an answer.
+func Confirm(message string, args...interface{}) (bool, error) {
+    return Options{}.Confirm(message, args...)
+}
+
+// Question displays a modal message box with message and "Yes", "No" and
+// "Cancel" buttons, and returns the button pressed by the user. The message
+// string can be a printf-style format string for an optional sequence of
+// additional arguments of any type.
+//
+// An error is returned if the dialog could not be displayed at all.
+func Question(message string, args...interface{}) (Answer, error) {
+    return Options{}.Question(message, args...)
+}
+
+// Prompt displays a modal dialog with a title, a message, and a single line
+// text entry initially containing defaultValue. It returns the text entered
+// and true, or false if the user cancelled the dialog.
+//
+// An error is returned if the dialog could not be displayed at all.
+func Prompt(title string, message string, defaultValue string) (string, bool, error) {
+    return Options{Title: title}.Prompt(message, defaultValue)
+}
+
+// Password is like Prompt, but the text entered by the user is masked and
+// there is no default value.
+func Password(title string, message string) (string, bool, error) {
+    return Options{Title: title}.Password(message)
 }
@@ -3,88 +3,509 @@
 package dialog // import "tawesoft.co.uk/go/dialog"
 
 import (
+    "context"
     "fmt"
     "os"
     "os/exec"
+    "path/filepath"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
 )
 
-type provider struct {
+// exitCode returns the exit status of a command that has been run, or -1 if
+// the command could not be run at all.
+func exitCode(err error) int {
+    if err == nil { return 0 }
+    var exitErr, ok = err.(*exec.ExitError)
+    if !ok { return -1 }
+    return exitErr.ExitCode()
+}
+
+// startPath returns the path that a file dialog should start at. Like most
+// tools, this is a directory followed by a default filename to pre-fill, so
+// a directory on its own must end with a slash.
+func startPath(req *Request) string {
+    if (len(req.Directory) == 0) && (len(req.Default) == 0) { return "" }
+
+    var path = filepath.Join(req.Directory, req.Default)
+    if len(req.Default) == 0 { path += string(filepath.Separator) }
+    return path
+}
+
+// xmessageProvider also supports gxmessage, a GTK clone of xmessage which can
+// additionally prompt for text.
+type xmessageProvider struct {
+    name string
     command string
-    alert func(self *provider, title string, message string, args...interface{}) bool
-}
-
-var xmessageProvider = provider{
-    command: "xmessage",
-    alert: func(self *provider, title string, message string, args...interface{}) bool {
-        var buf string = wrap(fmt.Sprintf(title+": "+message+"\n", args...), 60)
-
-        var err = exec.Command(self.command, "-center", buf).Run()
-        if err != nil { fmt.Printf("system error: %v\n", err) }
-        return err == nil
-    },
-}
-
-var zenityProvider = provider{
-    command: "zenity",
-    alert: func(self *provider, title string, message string, args...interface{}) bool {
-        var buf string = fmt.Sprintf(message, args...)
-
-        var err = exec.Command(self.command,
-            "--info", "--no-markup",
-            "--title", title,
-            "--window-icon", "info",
-            "--width=400",
-            "--text="+buf,
-        ).Run()
-
-        if err != nil {
-            var _, ok = err.(*exec.ExitError)
-            if ok {
-                // fine - we don't care about the return code
-                return true
+    entry bool // supports -entrytext
+}
+
+func (p xmessageProvider) Name() string { return p.name }
+
+func (p xmessageProvider) Show(ctx context.Context, req *Request) (Response, error) {
+    switch req.Kind {
+        case KindAlert:
+            return Response{}, exec.CommandContext(ctx, p.command, p.args(req)...).Run()
+
+        case KindConfirm:
+            var answer, err = p.buttons(ctx, req, "Yes:101,No:102")
+            return Response{Answer: answer}, err
+
+        case KindQuestion:
+            var answer, err = p.buttons(ctx, req, "Yes:101,No:102,Cancel:103")
+            return Response{Answer: answer}, err
+
+        case KindPrompt:
+            if !p.entry { return Response{}, ErrUnsupported }
+
+            var out, err = exec.CommandContext(ctx, p.command, p.args(req,
+                "-buttons", "OK:101,Cancel:102",
+                "-default", "OK",
+                "-entrytext", req.Default,
+            )...).Output()
+
+            switch code := exitCode(err); code {
+                case 101: return Response{Text: strings.TrimSuffix(string(out), "\n")}, nil
+                case 102: return Response{}, ErrCancelled
+                case -1:  return Response{}, err
+                default:  return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+            }
+
+        default:
+            return Response{}, ErrUnsupported
+    }
+}
+
+// buttons displays a message with the given xmessage -buttons specification,
+// where each button exits with a status of 100 plus its Answer.
+func (p xmessageProvider) buttons(ctx context.Context, req *Request, buttons string) (Answer, error) {
+    var args = []string{"-buttons", buttons}
+    if req.DefaultButton != 0 { args = append(args, "-default", req.DefaultButton.String()) }
+
+    var err = exec.CommandContext(ctx, p.command, p.args(req, args...)...).Run()
+    switch code := exitCode(err); code {
+        case 100 + int(Yes):    return Yes, nil
+        case 100 + int(No):     return No, nil
+        case 100 + int(Cancel): return Cancel, nil
+        case -1:                return 0, err
+        default:                return 0, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+    }
+}
+
+// args returns the arguments for an xmessage dialog, ending with the message.
+func (p xmessageProvider) args(req *Request, args ...string) []string {
+    var columns = 60
+
+    args = append([]string{"-center"}, args...)
+    if (req.Width > 0) && (req.Height > 0) {
+        args = append(args, "-geometry", fmt.Sprintf("%dx%d", req.Width, req.Height))
+    }
+    if req.Width > 0 {
+        columns = req.Width / 8 // assume a typical fixed-width font
+    }
+
+    var text = strings.Join(wrapLines(req.Title+": "+req.Message+"\n", columns), "\n")
+    if len(req.Details) > 0 { text += "\n\n" + req.Details }
+    return append(args, text)
+}
+
+type zenityProvider struct {
+    command string
+}
+
+func (p zenityProvider) Name() string { return "zenity" }
+
+func (p zenityProvider) Show(ctx context.Context, req *Request) (Response, error) {
+    switch req.Kind {
+        case KindAlert:
+            var kind, icon = "--info", "info"
+            switch req.Level {
+                case LevelWarning: kind, icon = "--warning", "warning"
+                case LevelError:   kind, icon = "--error", "error"
+            }
+
+            var args = append(p.args(req, kind),
+                "--window-icon", icon,
+                "--text="+zenityMarkup(req.Message),
+            )
+
+            // Options.Timeout kills zenity through ctx, instead of --timeout
+            var code, _, err = p.message(ctx, req, args)
+
+            switch code {
+                case 0, 1: return Response{}, nil // "OK", or the dialog was closed
+                case -1:   return Response{}, err
+                default:   return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+            }
+
+        case KindConfirm:
+            var args = append(p.args(req, "--question"), "--text="+zenityMarkup(req.Message))
+            if req.DefaultButton == No { args = append(args, "--default-cancel") }
+
+            var code, _, err = p.message(ctx, req, args)
+
+            switch code {
+                case 0:  return Response{Answer: Yes}, nil
+                case 1:  return Response{Answer: No}, nil // "No", or the dialog was closed
+                case -1: return Response{}, err
+                default: return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+            }
+
+        case KindQuestion:
+            // zenity has no native three-way question, so "Cancel" is an extra
+            // button that prints its label to stdout and exits with status 1.
+            var args = append(p.args(req, "--question"),
+                "--ok-label=Yes",
+                "--cancel-label=No",
+                "--extra-button=Cancel",
+                "--text="+zenityMarkup(req.Message),
+            )
+            if req.DefaultButton == No { args = append(args, "--default-cancel") }
+
+            var code, extra, err = p.message(ctx, req, args)
+
+            switch code {
+                case 0:  return Response{Answer: Yes}, nil
+                case 1:
+                    if extra == "Cancel" { return Response{Answer: Cancel}, nil }
+                    return Response{Answer: No}, nil
+                case -1: return Response{}, err
+                default: return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
             }
-            // other type of error
-            fmt.Printf("system error: %v\n", err)
+
+        case KindPrompt, KindPassword:
+            var args = append(p.args(req, "--entry"),
+                "--text="+zenityMarkup(req.Message),
+                "--entry-text="+req.Default,
+            )
+            if req.Kind == KindPassword { args = append(args, "--hide-text") }
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            return p.result(err, func() Response {
+                return Response{Text: strings.TrimSuffix(string(out), "\n")}
+            })
+
+        case KindOpenFile, KindOpenFiles, KindSaveFile, KindSelectFolder:
+            var args = p.args(req, "--file-selection")
+
+            switch req.Kind {
+                case KindOpenFiles:    args = append(args, "--multiple", "--separator=\n")
+                case KindSaveFile:     args = append(args, "--save", "--confirm-overwrite")
+                case KindSelectFolder: args = append(args, "--directory")
+            }
+
+            if path := startPath(req); len(path) > 0 {
+                args = append(args, "--filename="+path)
+            }
+
+            for _, f := range req.Filters {
+                args = append(args, "--file-filter="+f.Name+" | "+strings.Join(f.Patterns, " "))
+            }
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            return p.result(err, func() Response {
+                return Response{Paths: strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")}
+            })
+
+        case KindChoose, KindChooseMany:
+            // each row is a hidden item number (counting from 1), which is
+            // printed for each selected row, followed by the item itself
+            var args = p.args(req, "--list", "--hide-header")
+            if req.Height == 0 { args = append(args, "--height=300") }
+            args = append(args, "--text="+zenityMarkup(req.Message))
+
+            if req.Kind == KindChooseMany {
+                args = append(args, "--checklist", "--column=", "--column=#", "--column=Item",
+                    "--hide-column=2", "--print-column=2", "--separator=\n")
+            } else {
+                args = append(args, "--column=#", "--column=Item",
+                    "--hide-column=1", "--print-column=1")
+            }
+
+            for i, item := range req.Items {
+                if req.Kind == KindChooseMany { args = append(args, "FALSE") }
+                args = append(args, strconv.Itoa(i + 1), item)
+            }
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            if err != nil { return p.result(err, nil) }
+            return choiceResponse(p.command, string(out), len(req.Items))
+
+        case KindPickDate:
+            var args = append(p.args(req, "--calendar"),
+                "--text="+zenityMarkup(req.Message),
+                "--date-format=%Y-%m-%d",
+                fmt.Sprintf("--day=%d", req.Date.Day()),
+                fmt.Sprintf("--month=%d", req.Date.Month()),
+                fmt.Sprintf("--year=%d", req.Date.Year()),
+            )
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            if err != nil { return p.result(err, nil) }
+            return pickResponse(p.command, req, string(out))
+
+        case KindPickColor:
+            // older versions print colours in the form #rrrrggggbbbb
+            var c = req.Color
+            var args = append(p.args(req, "--color-selection"),
+                fmt.Sprintf("--color=rgb(%d,%d,%d)", c.R, c.G, c.B),
+                "--show-palette",
+            )
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            if err != nil { return p.result(err, nil) }
+            return pickResponse(p.command, req, string(out))
+
+        case KindPickNumber:
+            var args = append(p.args(req, "--scale"),
+                "--text="+zenityMarkup(req.Message),
+                fmt.Sprintf("--min-value=%d", req.Min),
+                fmt.Sprintf("--max-value=%d", req.Max),
+                fmt.Sprintf("--step=%d", req.Step),
+                fmt.Sprintf("--value=%d", req.Number),
+            )
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            if err != nil { return p.result(err, nil) }
+            return pickResponse(p.command, req, string(out))
+
+        default:
+            return Response{}, ErrUnsupported
+    }
+}
+
+// message runs a zenity message dialog, with an extra "Details" button if
+// there are details. Each time that button is pressed, the details are shown
+// in a separate window, then the message is shown again. It returns the exit
+// status of zenity and the label of any other extra button pressed, for the
+// caller to interpret.
+func (p zenityProvider) message(ctx context.Context, req *Request, args []string) (int, string, error) {
+    if len(req.Details) > 0 { args = append(args, "--extra-button=Details") }
+
+    for {
+        var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+        var code, extra = exitCode(err), strings.TrimSpace(string(out))
+        if code < 0 { return code, "", err }
+
+        if (code == 1) && (extra == "Details") && (len(req.Details) > 0) {
+            if err := p.details(ctx, req); err != nil { return -1, "", err }
+            continue
         }
-        return err == nil
-    },
+
+        return code, extra, nil
+    }
 }
 
-var stdioProvider = provider{
-    command: "",
-    alert: func(_self *provider, title string, message string, args...interface{}) bool {
-        fmt.Fprintf(os.Stderr, "\n===[%s]===\n\n", title)
-        fmt.Fprintf(os.Stderr, message, args...)
-        fmt.Fprintf(os.Stdout, "\n\n=========\n\n")
-        return true
-    },
+// details shows the details of a request as scrollable, monospaced text.
+func (p zenityProvider) details(ctx context.Context, req *Request) error {
+    var cmd = exec.CommandContext(ctx, p.command, "--text-info",
+        "--title", req.Title,
+        "--width=600",
+        "--height=400",
+        "--font=monospace",
+    )
+    cmd.Stdin = strings.NewReader(req.Details)
+
+    var err = cmd.Run()
+    switch code := exitCode(err); code {
+        case 0, 1: return nil // "OK", or the window was closed
+        case -1:   return err
+        default:   return fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+    }
 }
 
-var providers []*provider
+// zenityLink matches a web link, excluding any punctuation that follows it.
+var zenityLink = regexp.MustCompile(`https?://[^\s<>"]*[^\s<>".,;:!?)]`)
 
-func (p *provider) register() {
-    if len(p.command) == 0 || haveCommand(p.command) {
-        providers = append(providers, p)
+// zenityMarkup escapes text so that it is displayed as-is by zenity, which
+// interprets Pango markup, except that web links can be clicked.
+func zenityMarkup(text string) string {
+    var b strings.Builder
+    var last = 0
+
+    for _, match := range zenityLink.FindAllStringIndex(text, -1) {
+        var link = escapeMarkup(text[match[0]:match[1]])
+        b.WriteString(escapeMarkup(text[last:match[0]]))
+        fmt.Fprintf(&b, `<a href="%s">%s</a>`, link, link)
+        last = match[1]
     }
+
+    b.WriteString(escapeMarkup(text[last:]))
+    return b.String()
 }
 
-func init() {
-    providers = make([]*provider, 0, 4)
+// choiceResponse returns a Response for the output of a program that prints
+// the number of each selected item, counting from 1.
+func choiceResponse(command string, out string, n int) (Response, error) {
+    var choices, ok = parseChoices(out, n)
+    if !ok { return Response{}, fmt.Errorf("%s: unexpected output %q", command, out) }
+    return Response{Choices: choices}, nil
+}
+
+// pickResponse returns a Response for the output of a program that prints a
+// date, colour or number in a form understood by parsePick.
+func pickResponse(command string, req *Request, out string) (Response, error) {
+    out = strings.TrimSpace(out)
+
+    // a slider doesn't necessarily keep to each step
+    if req.Kind == KindPickNumber {
+        if n, err := strconv.Atoi(out); err == nil {
+            out = strconv.Itoa(snapNumber(n, req.Min, req.Max, req.Step))
+        }
+    }
+
+    var response, err = parsePick(req, out)
+    if (err != nil) || (len(out) == 0) {
+        return Response{}, fmt.Errorf("%s: unexpected output %q", command, out)
+    }
+    return response, nil
+}
+
+// isoDate returns a date printed in one of the given layouts in the form
+// understood by parsePick, or returns it unchanged if it is in none of them.
+func isoDate(out string, layouts ...string) string {
+    out = strings.TrimSpace(out)
+    for _, layout := range layouts {
+        if date, err := time.Parse(layout, out); err == nil { return date.Format(dateFormat) }
+    }
+    return out
+}
+
+// args returns the arguments for a kind of zenity dialog, followed by any
+// arguments common to every kind of dialog.
+func (p zenityProvider) args(req *Request, kind ...string) []string {
+    var args = append(kind, "--title", req.Title)
+
+    if req.Width > 0 {
+        args = append(args, fmt.Sprintf("--width=%d", req.Width))
+    } else if !req.Kind.isFile() {
+        args = append(args, "--width=400")
+    }
 
-    // register in order of preference
-    zenityProvider.register()
-    xmessageProvider.register()
-    stdioProvider.register()
+    if req.Height > 0 {
+        args = append(args, fmt.Sprintf("--height=%d", req.Height))
+    }
+    if req.Parent != 0 {
+        args = append(args, fmt.Sprintf("--attach=%d", req.Parent))
+    }
+
+    return args
+}
+
+// result interprets the exit status of a zenity dialog that returns its
+// result on stdout, where an exit status of 1 means it was cancelled.
+func (p zenityProvider) result(err error, f func() Response) (Response, error) {
+    switch code := exitCode(err); code {
+        case 0:  return f(), nil
+        case 1:  return Response{}, ErrCancelled
+        case -1: return Response{}, err
+        default: return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+    }
 }
 
-func haveCommand(cmd string) bool {
-    // TODO use exec.Lookpath instead of which
-    return (exec.Command("sh", "-c", "which "+cmd+" > /dev/null 2>&1").Run() == nil)
+// linuxSession describes the graphical session, from environment variables.
+type linuxSession struct {
+    desktop string // $XDG_CURRENT_DESKTOP
+    x11 bool       // $DISPLAY is set
+    wayland bool   // $WAYLAND_DISPLAY is set
+    bus string     // $DBUS_SESSION_BUS_ADDRESS
 }
 
-func platformAlert(title string, message string, args...interface{}) {
-    for _, provider := range(providers) {
-        if provider.alert == nil { continue }
-        if provider.alert(provider, title, message, args...) { break }
+// linuxProviders returns a provider for each supported command that is
+// installed, in order of preference for the session's desktop environment,
+// followed by tty and stdio. Without a display, only the providers that don't
+// need one are returned.
+//
+// With a session bus, the xdg-desktop-portal provider is also returned: first
+// on Wayland, where it gives native file dialogs, and otherwise after the
+// installed commands.
+func linuxProviders(session linuxSession, have func(command string) bool) []Provider {
+    var preferred = []Provider{
+        zenityProvider{command: "zenity"},
+        kdialogProvider{command: "kdialog"},
+        yadProvider{command: "yad"},
+        xmessageProvider{name: "gxmessage", command: "gxmessage", entry: true},
+        xdialogProvider{command: "Xdialog"},
+        xmessageProvider{name: "xmessage", command: "xmessage"},
+        notifySendProvider{command: "notify-send"},
+    }
+
+    // prefer the native look on Qt-based desktops
+    var qt = false
+    for _, name := range strings.Split(session.desktop, ":") {
+        switch strings.ToUpper(name) {
+            case "KDE", "LXQT", "TDE", "TRINITY": qt = true
+        }
+    }
+    if qt { preferred[0], preferred[1] = preferred[1], preferred[0] }
+
+    var display = session.x11 || session.wayland
+    var portal = display && (len(session.bus) > 0)
+
+    var providers = make([]Provider, 0, len(preferred) + 3)
+    if portal && session.wayland {
+        providers = append(providers, portalProvider{address: session.bus})
+    }
+    for _, p := range preferred {
+        if !display && (p.Name() != "notify-send") { continue }
+        if have(p.(programProvider).program()) { providers = append(providers, p) }
+    }
+    if portal && !session.wayland {
+        providers = append(providers, portalProvider{address: session.bus})
+    }
+    return append(providers, ttyProvider{}, stdioProvider{})
+}
+
+// programProvider is implemented by providers that run an external program.
+type programProvider interface {
+    program() string
+}
+
+func (p xmessageProvider) program() string   { return p.command }
+func (p zenityProvider) program() string     { return p.command }
+func (p kdialogProvider) program() string    { return p.command }
+func (p yadProvider) program() string        { return p.command }
+func (p xdialogProvider) program() string    { return p.command }
+func (p notifySendProvider) program() string { return p.command }
+
+func haveCommand(command string) bool {
+    var _, err = exec.LookPath(command)
+    return err == nil
+}
+
+// programVersion returns the first line printed by a program when asked for
+// its version, or an empty string if it doesn't understand the question.
+func programVersion(path string) string {
+    var ctx, cancel = context.WithTimeout(context.Background(), 2 * time.Second)
+    defer cancel()
+
+    var out, err = exec.CommandContext(ctx, path, "--version").Output()
+    if err != nil { return "" }
+
+    for _, line := range strings.Split(string(out), "\n") {
+        if line = strings.TrimSpace(line); len(line) > 0 { return line }
+    }
+    return ""
+}
+
+func init() {
+    discover = func() []Provider {
+        return linuxProviders(linuxSession{
+            desktop: os.Getenv("XDG_CURRENT_DESKTOP"),
+            x11:     os.Getenv("DISPLAY") != "",
+            wayland: os.Getenv("WAYLAND_DISPLAY") != "",
+            bus:     os.Getenv("DBUS_SESSION_BUS_ADDRESS"),
+        }, haveCommand)
+    }
+
+    describe = func(p Provider) Backend {
+        var b = Backend{Name: p.Name()}
+        if pp, ok := p.(programProvider); ok {
+            b.Path, _ = exec.LookPath(pp.program())
+            if len(b.Path) > 0 { b.Version = programVersion(b.Path) }
+        }
+        return b
     }
 }
@@ -0,0 +1,230 @@
+// +build linux
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io/ioutil"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestLinuxProviders(t *testing.T) {
+    var tests = []struct{
+        display string
+        bus string
+        desktop string
+        installed string
+        expected string
+    }{
+        {"x11",     "",    "",              "",                        "tty stdio"},
+        {"x11",     "",    "",              "xmessage zenity kdialog", "zenity kdialog xmessage tty stdio"},
+        {"x11",     "",    "GNOME",         "xmessage zenity kdialog", "zenity kdialog xmessage tty stdio"},
+        {"x11",     "",    "KDE",           "xmessage zenity kdialog", "kdialog zenity xmessage tty stdio"},
+        {"x11",     "",    "ubuntu:KDE",    "xmessage zenity kdialog", "kdialog zenity xmessage tty stdio"},
+        {"x11",     "",    "KDE:TDE",       "xmessage zenity kdialog", "kdialog zenity xmessage tty stdio"},
+        {"x11",     "",    "KDE",           "yad gxmessage Xdialog",   "yad gxmessage Xdialog tty stdio"},
+        {"x11",     "",    "",              "notify-send zenity",      "zenity notify-send tty stdio"},
+        {"",        "",    "",              "xmessage zenity kdialog", "tty stdio"},
+        {"",        "",    "",              "notify-send zenity",      "notify-send tty stdio"},
+        {"x11",     "bus", "",              "zenity",                  "zenity portal tty stdio"},
+        {"wayland", "bus", "",              "zenity",                  "portal zenity tty stdio"},
+        {"both",    "bus", "KDE",           "zenity kdialog",          "portal kdialog zenity tty stdio"},
+        {"wayland", "",    "",              "zenity",                  "zenity tty stdio"},
+        {"",        "bus", "",              "notify-send",             "notify-send tty stdio"},
+    }
+
+    for index, test := range tests {
+        var installed = strings.Fields(test.installed)
+        var have = func(command string) bool {
+            for _, x := range installed {
+                if x == command { return true }
+            }
+            return false
+        }
+
+        var names = make([]string, 0)
+        var session = linuxSession{
+            desktop: test.desktop,
+            x11:     (test.display == "x11") || (test.display == "both"),
+            wayland: (test.display == "wayland") || (test.display == "both"),
+            bus:     test.bus,
+        }
+
+        for _, p := range linuxProviders(session, have) {
+            names = append(names, p.Name())
+        }
+
+        var result = strings.Join(names, " ")
+        if result != test.expected {
+            t.Errorf("Test %d: got %q but wanted %q", index, result, test.expected)
+        }
+    }
+}
+
+func TestZenityMarkup(t *testing.T) {
+    var tests = []struct{
+        input string
+        expected string
+    }{
+        {"plain text", "plain text"},
+        {"<b>a & b</b>", "&lt;b&gt;a &amp; b&lt;/b&gt;"},
+        {"see https://example.org/a?b=1&c=2.",
+            `see <a href="https://example.org/a?b=1&amp;c=2">https://example.org/a?b=1&amp;c=2</a>.`},
+        {"(http://example.org/)", `(<a href="http://example.org/">http://example.org/</a>)`},
+        {"<http://example.org>", `&lt;<a href="http://example.org">http://example.org</a>&gt;`},
+    }
+
+    for index, test := range tests {
+        if result := zenityMarkup(test.input); result != test.expected {
+            t.Errorf("Test %d: got %q but wanted %q", index, result, test.expected)
+        }
+    }
+}
+
+// fakeCommand returns the path to a shell script that runs the given
+// commands, in place of a program such as zenity.
+func fakeCommand(t *testing.T, script string) string {
+    var path = filepath.Join(t.TempDir(), "fake")
+    var err = ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0700)
+    if err != nil { t.Fatal(err) }
+    return path
+}
+
+func TestAlertExitStatus(t *testing.T) {
+    var errUnexpected = errors.New("unexpected exit status")
+
+    var tests = []struct{
+        provider func(command string) Provider
+        status int
+        expected error
+    }{
+        {func(c string) Provider { return zenityProvider{command: c} },  0,   nil},
+        {func(c string) Provider { return zenityProvider{command: c} },  1,   nil},
+        {func(c string) Provider { return zenityProvider{command: c} },  5,   errUnexpected},
+        {func(c string) Provider { return zenityProvider{command: c} },  255, errUnexpected},
+        {func(c string) Provider { return kdialogProvider{command: c} }, 1,   nil},
+        {func(c string) Provider { return kdialogProvider{command: c} }, 2,   errUnexpected},
+        {func(c string) Provider { return yadProvider{command: c} },     252, nil},
+        {func(c string) Provider { return yadProvider{command: c} },     70,  errUnexpected},
+        {func(c string) Provider { return xdialogProvider{command: c} }, 255, nil},
+        {func(c string) Provider { return xdialogProvider{command: c} }, 1,   errUnexpected},
+    }
+
+    for index, test := range tests {
+        var provider = test.provider(fakeCommand(t, fmt.Sprintf("exit %d", test.status)))
+        var _, err = provider.Show(context.Background(), &Request{Kind: KindAlert})
+
+        if test.expected == errUnexpected {
+            if (err == nil) || !strings.Contains(err.Error(), test.expected.Error()) {
+                t.Errorf("Test %d: got %v but wanted %v", index, err, test.expected)
+            }
+        } else if err != test.expected {
+            t.Errorf("Test %d: got %v but wanted %v", index, err, test.expected)
+        }
+    }
+}
+
+func TestChooseOutput(t *testing.T) {
+    var tests = []struct{
+        provider Provider
+        kind Kind
+        expected []int
+    }{
+        {yadProvider{command: fakeCommand(t, `echo 2`)},                      KindChoose,     []int{1}},
+        {yadProvider{command: fakeCommand(t, `printf '1\n\n3\n\n'`)},         KindChooseMany, []int{0, 2}},
+        {xdialogProvider{command: fakeCommand(t, `echo 3 >&2`)},              KindChoose,     []int{2}},
+        {xdialogProvider{command: fakeCommand(t, `printf '1\n2\n' >&2`)},     KindChooseMany, []int{0, 1}},
+    }
+
+    for index, test := range tests {
+        var req = &Request{Kind: test.kind, Items: []string{"a", "b", "c"}}
+        var response, err = test.provider.Show(context.Background(), req)
+        if err != nil {
+            t.Errorf("Test %d: unexpected error %v", index, err)
+        } else if !reflect.DeepEqual(response.Choices, test.expected) {
+            t.Errorf("Test %d: got %v but wanted %v", index, response.Choices, test.expected)
+        }
+    }
+}
+
+func TestPickOutput(t *testing.T) {
+    var date = time.Date(2021, 12, 25, 0, 0, 0, 0, time.Local)
+    var tests = []struct{
+        provider Provider
+        kind Kind
+        expected Response
+    }{
+        {kdialogProvider{command: fakeCommand(t, `echo 'Sat Dec 25 2021'`)}, KindPickDate,   Response{Date: date}},
+        {kdialogProvider{command: fakeCommand(t, `echo 2021-12-25`)},        KindPickDate,   Response{Date: date}},
+        {xdialogProvider{command: fakeCommand(t, `echo 25/12/2021 >&2`)},    KindPickDate,   Response{Date: date}},
+        {xdialogProvider{command: fakeCommand(t, `echo 8 >&2`)},             KindPickNumber, Response{Number: 7}},
+    }
+
+    for index, test := range tests {
+        var req = &Request{Kind: test.kind, Date: time.Now(), Min: 1, Max: 10, Step: 3, Number: 4}
+        var response, err = test.provider.Show(context.Background(), req)
+        if err != nil {
+            t.Errorf("Test %d: unexpected error %v", index, err)
+        } else if !response.Date.Equal(test.expected.Date) || (response.Number != test.expected.Number) {
+            t.Errorf("Test %d: got %+v but wanted %+v", index, response, test.expected)
+        }
+    }
+
+    // a date in a form that can't be understood
+    var req = &Request{Kind: KindPickDate, Date: time.Now()}
+    var _, err = kdialogProvider{command: fakeCommand(t, `echo 'sam. déc. 25 2021'`)}.Show(context.Background(), req)
+    if err == nil { t.Errorf("expected an error") }
+}
+
+func TestPipeProgressPulse(t *testing.T) {
+    // the fake command logs whether it was started with --pulsate
+    var log = filepath.Join(t.TempDir(), "log")
+    var command = fakeCommand(t, `
+        mode=percent
+        for arg in "$@"; do [ "$arg" = --pulsate ] && mode=pulsate; done
+        echo $mode >> '`+log+`'
+        cat > /dev/null`)
+
+    // waits for the nth process to start, and returns every mode so far
+    var started = func(n int) string {
+        for start := time.Now(); time.Since(start) < 5 * time.Second; time.Sleep(10 * time.Millisecond) {
+            var data, _ = ioutil.ReadFile(log)
+            if strings.Count(string(data), "\n") >= n { return strings.Join(strings.Fields(string(data)), " ") }
+        }
+        t.Fatalf("process %d didn't start", n)
+        return ""
+    }
+
+    var view, err = zenityProvider{command: command}.ShowProgress(context.Background(), &Request{}, func() {
+        t.Errorf("unexpected cancel")
+    })
+    if err != nil { t.Fatal(err) }
+    started(1)
+
+    // Pulse and SetPercent each restart the process if the mode changes
+    var steps = []struct{
+        update func() error
+        expected string
+    }{
+        {func() error { return view.Pulse() },        "percent pulsate"},
+        {func() error { return view.Pulse() },        "percent pulsate"},
+        {func() error { return view.SetPercent(50) }, "percent pulsate percent"},
+        {func() error { return view.SetPercent(60) }, "percent pulsate percent"},
+    }
+
+    for index, step := range steps {
+        if err := step.update(); err != nil { t.Fatalf("Test %d: unexpected error %v", index, err) }
+        var n = len(strings.Fields(step.expected))
+        if result := started(n); result != step.expected {
+            t.Errorf("Test %d: got %q but wanted %q", index, result, step.expected)
+        }
+    }
+
+    if err := view.Close(); err != nil { t.Fatal(err) }
+}
@@ -3,13 +3,19 @@
 package dialog // import "tawesoft.co.uk/go/dialog"
 
 import (
+    "context"
     "fmt"
+    "runtime"
+    "time"
+    "unsafe"
     "golang.org/x/sys/windows"
 )
 
 
 const cp_utf8 uint32 = 65001;
 
+var user32 = windows.NewLazySystemDLL("user32.dll")
+
 
 func toWideChar(input string) []uint16 {
 
@@ -54,24 +60,196 @@ func toWideChar(input string) []uint16 {
         return make([]uint16, 1) // empty null-terminated string
 }
 
-func platformAlert(title string, message string, args...interface{}) {
-    var msg string
+// callError returns the error for a Windows API call that has failed, by its
+// return value, given the last error captured by LazyProc.Call. Some calls
+// fail without setting a last error, so this is never Errno(0).
+func callError(name string, err error) error {
+    if errno, ok := err.(windows.Errno); ok && (errno == 0) {
+        return fmt.Errorf("dialog: %s failed", name)
+    }
+    return err
+}
+
+// MessageBox return values
+const (
+    idCancel   int32 = 2
+    idYes      int32 = 6
+    idNo       int32 = 7
+    idTimedOut int32 = 32000
+)
+
+// MessageBoxTimeoutW is undocumented, but has been available since Windows XP.
+var procMessageBoxTimeoutW = user32.NewProc("MessageBoxTimeoutW")
+
+// messageBox displays a MessageBox for a request, honouring its options.
+// Button is the position of each possible Answer, starting from 1, or zero.
+func messageBox(req *Request, flags uint32, buttons map[Answer]int) (int32, error) {
+    var wtitle = toWideChar(req.Title)
+    var wmessage = toWideChar(req.PlainMessage())
+
+    flags |= windows.MB_SETFOREGROUND | windows.MB_TOPMOST
+
+    switch buttons[req.DefaultButton] {
+        case 2: flags |= windows.MB_DEFBUTTON2
+        case 3: flags |= windows.MB_DEFBUTTON3
+    }
+
+    if req.Timeout <= 0 {
+        return windows.MessageBox(windows.HWND(req.Parent), &wmessage[0], &wtitle[0], flags)
+    }
+
+    var result, _, err = procMessageBoxTimeoutW.Call(
+        req.Parent,
+        uintptr(unsafe.Pointer(&wmessage[0])),
+        uintptr(unsafe.Pointer(&wtitle[0])),
+        uintptr(flags),
+        0, // default language
+        uintptr(req.Timeout.Milliseconds()),
+    )
+
+    switch int32(result) {
+        case 0:          return 0, callError("MessageBoxTimeoutW", err)
+        case idTimedOut: return 0, ErrTimeout
+        default:         return int32(result), nil
+    }
+}
+
+func messageBoxIcon(level Level) uint32 {
+    switch level {
+        case LevelInfo:    return windows.MB_ICONINFORMATION
+        case LevelWarning: return windows.MB_ICONWARNING
+        case LevelError:   return windows.MB_ICONERROR
+        default:           return windows.MB_ICONEXCLAMATION
+    }
+}
+
+// windowsProvider displays dialogs using the Windows API.
+type windowsProvider struct{}
+
+func (windowsProvider) Name() string { return "windows" }
 
-    if len(args) > 0 {
-        msg = fmt.Sprintf(message, args...)
-    } else {
-        msg = message
+func (p windowsProvider) Show(ctx context.Context, req *Request) (response Response, err error) {
+    // the button pressed to close each kind of dialog early
+    var button = uintptr(idCancel)
+    switch req.Kind {
+        case KindAlert:   button = idOk
+        case KindConfirm: button = uintptr(idNo)
+        case KindNotify:  return Response{}, windowsNotify(ctx, req) // not modal
     }
 
-    var wtitle = toWideChar(title)
-    var wmessage = toWideChar(msg)
-    var flags uint32 = windows.MB_OK |
-        windows.MB_ICONEXCLAMATION |
-        windows.MB_SETFOREGROUND |
-        windows.MB_TOPMOST
+    closeOnDone(ctx, button, func() {
+        response, err = p.display(req)
+    })
+    return response, err
+}
+
+// display displays a dialog on the current thread.
+func (windowsProvider) display(req *Request) (Response, error) {
+    switch req.Kind {
+        case KindAlert:
+            var _, err = messageBox(req, windows.MB_OK | messageBoxIcon(req.Level), nil)
+            return Response{}, err
+
+        case KindConfirm:
+            var result, err = messageBox(req, windows.MB_YESNO | windows.MB_ICONQUESTION,
+                map[Answer]int{Yes: 1, No: 2})
+            if err != nil { return Response{}, err }
+            if result == idYes { return Response{Answer: Yes}, nil }
+            return Response{Answer: No}, nil
+
+        case KindQuestion:
+            var result, err = messageBox(req, windows.MB_YESNOCANCEL | windows.MB_ICONQUESTION,
+                map[Answer]int{Yes: 1, No: 2, Cancel: 3})
+            if err != nil { return Response{}, err }
+
+            switch result {
+                case idYes:    return Response{Answer: Yes}, nil
+                case idNo:     return Response{Answer: No}, nil
+                case idCancel: return Response{Answer: Cancel}, nil
+                default:       return Response{}, fmt.Errorf("MessageBox: unexpected result %d", result)
+            }
+
+        case KindPrompt, KindPassword:
+            var text, ok, err = windowsPrompt(req)
+            if (err == nil) && !ok { err = ErrCancelled }
+            return Response{Text: text}, err
+
+        case KindOpenFile, KindOpenFiles, KindSaveFile, KindSelectFolder:
+            var paths, ok, err = windowsFile(req)
+            if (err == nil) && !ok { err = ErrCancelled }
+            return Response{Paths: paths}, err
+
+        case KindPickColor:
+            var c, ok, err = windowsColor(req)
+            if (err == nil) && !ok { err = ErrCancelled }
+            return Response{Color: c}, err
+
+        case KindPickDate, KindPickNumber:
+            return windowsPickText(req)
+
+        default:
+            return Response{}, ErrUnsupported
+    }
+}
 
-    windows.MessageBox(0, &wmessage[0], &wtitle[0], flags)
+var (
+    procEnumThreadWindows = user32.NewProc("EnumThreadWindows")
+    procPostMessageW      = user32.NewProc("PostMessageW")
+)
+
+// closeOnDone calls f, which displays a modal dialog, on a thread of its own.
+// If ctx is done before f returns, the dialog is closed early as if the user
+// had pressed the button with the given ID, such as IDCANCEL.
+func closeOnDone(ctx context.Context, button uintptr, f func()) {
+    runtime.LockOSThread()
+    defer runtime.UnlockOSThread()
+
+    var thread = windows.GetCurrentThreadId()
+    var done = make(chan struct{})
+    defer close(done)
+
+    go func() {
+        select {
+            case <-done: return
+            case <-ctx.Done():
+        }
+
+        // the dialog may not have been created yet, so keep trying
+        var ticker = time.NewTicker(100 * time.Millisecond)
+        defer ticker.Stop()
+
+        for {
+            procEnumThreadWindows.Call(uintptr(thread), closeWindowCallback, button)
+            select {
+                case <-done: return
+                case <-ticker.C:
+            }
+        }
+    }()
+
+    f()
+}
+
+// closeWindowCallback is an EnumThreadWindows callback that sends each
+// window a command from the button with the ID given by lparam.
+var closeWindowCallback = windows.NewCallback(func(hwnd uintptr, lparam uintptr) uintptr {
+    procPostMessageW.Call(hwnd, wmCommand, lparam, 0)
+    return 1 // continue
+})
+
+func init() {
+    discover = func() []Provider {
+        return []Provider{windowsProvider{}, stdioProvider{}}
+    }
+
+    describe = func(p Provider) Backend {
+        var b = Backend{Name: p.Name()}
+        if _, ok := p.(windowsProvider); ok {
+            var v = windows.RtlGetVersion()
+            b.Version = fmt.Sprintf("Windows %d.%d.%d", v.MajorVersion, v.MinorVersion, v.BuildNumber)
+        }
+        return b
+    }
 
-    wtitle = nil
-    wmessage = nil
+    closeNotifications = windowsCloseNotifications
 }
@@ -0,0 +1,171 @@
+// Package dialogtest helps to test code that displays dialogs with package
+// dialog, without displaying anything.
+//
+// Install replaces every dialog provider with a Recorder, which records each
+// dialog and responds to it with scripted responses:
+//
+//     func TestDelete(t *testing.T) {
+//         var r = dialogtest.Install(t)
+//         r.Answer(dialog.Yes)
+//
+//         deleteFiles() // calls dialog.Confirm
+//
+//         var calls = r.Calls()
+//         if (len(calls) != 1) || (calls[0].Kind != dialog.KindConfirm) {
+//             t.Errorf("expected a confirmation, got %+v", calls)
+//         }
+//     }
+package dialogtest // import "tawesoft.co.uk/go/dialog/dialogtest"
+
+import (
+    "context"
+    "image/color"
+    "os"
+    "sync"
+    "testing"
+    "time"
+
+    "tawesoft.co.uk/go/dialog"
+    "tawesoft.co.uk/go/dialog/internal/hooks"
+)
+
+// Name is the name of a Recorder as a dialog.Provider.
+const Name = "dialogtest"
+
+// Recorder is a dialog.Provider that records each dialog, and responds to
+// each one that asks the user for something with the next scripted response,
+// in order. Alert and Notify dialogs always succeed, without using a scripted
+// response.
+//
+// When there are no scripted responses left, a dialog returns
+// dialog.ErrCancelled as if the user had closed it.
+//
+// A Recorder is safe to use from multiple goroutines.
+type Recorder struct {
+    mutex sync.Mutex
+    calls []dialog.Request
+    script []step
+}
+
+type step struct {
+    response dialog.Response
+    err error
+}
+
+// Install registers a new Recorder as the only dialog provider until the test
+// ends, when the previous providers are restored exactly as they were, so
+// that built-in providers are still discovered when first needed. It also
+// clears the dialog.EnvProviders environment variable until then.
+func Install(t testing.TB) *Recorder {
+    var r = &Recorder{}
+
+    t.Cleanup(hooks.SaveProviders())
+    os.Unsetenv(dialog.EnvProviders)
+    dialog.SetProviders(r)
+
+    return r
+}
+
+func (r *Recorder) Name() string { return Name }
+
+func (r *Recorder) Show(ctx context.Context, req *dialog.Request) (dialog.Response, error) {
+    r.mutex.Lock()
+    defer r.mutex.Unlock()
+
+    r.calls = append(r.calls, *req)
+
+    switch {
+        case (req.Kind == dialog.KindAlert) || (req.Kind == dialog.KindNotify):
+            return dialog.Response{}, nil
+        case len(r.script) == 0:
+            return dialog.Response{}, dialog.ErrCancelled
+    }
+
+    var s = r.script[0]
+    r.script = r.script[1:]
+    return s.response, s.err
+}
+
+// ShowProgress records a progress dialog, which ignores any updates.
+func (r *Recorder) ShowProgress(ctx context.Context, req *dialog.Request, cancel func()) (dialog.ProgressView, error) {
+    r.mutex.Lock()
+    defer r.mutex.Unlock()
+
+    r.calls = append(r.calls, *req)
+    return progressView{}, nil
+}
+
+type progressView struct{}
+
+func (progressView) SetPercent(percent int) error { return nil }
+func (progressView) SetText(text string) error    { return nil }
+func (progressView) Pulse() error                 { return nil }
+func (progressView) Close() error                 { return nil }
+
+// Calls returns a copy of each dialog request received so far, in order.
+func (r *Recorder) Calls() []dialog.Request {
+    r.mutex.Lock()
+    defer r.mutex.Unlock()
+
+    return append([]dialog.Request(nil), r.calls...)
+}
+
+// Reset forgets all recorded calls and any scripted responses that are left.
+func (r *Recorder) Reset() {
+    r.mutex.Lock()
+    defer r.mutex.Unlock()
+
+    r.calls = nil
+    r.script = nil
+}
+
+// Respond adds a response to the end of the script. If err is not nil, it is
+// returned instead, as if by a real provider.
+func (r *Recorder) Respond(response dialog.Response, err error) {
+    r.mutex.Lock()
+    defer r.mutex.Unlock()
+
+    r.script = append(r.script, step{response, err})
+}
+
+// Answer adds an answer for a Confirm or Question dialog to the script.
+func (r *Recorder) Answer(answer dialog.Answer) {
+    r.Respond(dialog.Response{Answer: answer}, nil)
+}
+
+// Text adds text entered into a Prompt or Password dialog to the script.
+func (r *Recorder) Text(text string) {
+    r.Respond(dialog.Response{Text: text}, nil)
+}
+
+// Paths adds paths selected in a file or folder dialog to the script.
+func (r *Recorder) Paths(paths ...string) {
+    r.Respond(dialog.Response{Paths: paths}, nil)
+}
+
+// Choices adds the indexes of items selected in a Choose or ChooseMany dialog
+// to the script.
+func (r *Recorder) Choices(indexes ...int) {
+    r.Respond(dialog.Response{Choices: indexes}, nil)
+}
+
+// Date adds a date selected in a PickDate dialog to the script.
+func (r *Recorder) Date(date time.Time) {
+    r.Respond(dialog.Response{Date: date}, nil)
+}
+
+// Color adds a colour selected in a PickColor dialog to the script.
+func (r *Recorder) Color(c color.RGBA) {
+    r.Respond(dialog.Response{Color: c}, nil)
+}
+
+// Number adds a number selected in a PickNumber dialog to the script.
+func (r *Recorder) Number(n int) {
+    r.Respond(dialog.Response{Number: n}, nil)
+}
+
+// Cancel adds a response to the script for a dialog that the user closed
+// without answering it.
+func (r *Recorder) Cancel() {
+    r.Respond(dialog.Response{}, dialog.ErrCancelled)
+}
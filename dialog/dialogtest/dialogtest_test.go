@@ -0,0 +1,125 @@
+package dialogtest_test
+
+import (
+    "errors"
+    "image/color"
+    "reflect"
+    "testing"
+    "time"
+
+    "tawesoft.co.uk/go/dialog"
+    "tawesoft.co.uk/go/dialog/dialogtest"
+)
+
+func TestRecorder(t *testing.T) {
+    var r = dialogtest.Install(t)
+    r.Answer(dialog.Yes)
+    r.Text("guest")
+    r.Cancel()
+    r.Choices(0, 2)
+    r.Respond(dialog.Response{}, errors.New("broken"))
+
+    if err := dialog.Warning("Low disk space"); err != nil {
+        t.Errorf("Warning: unexpected error %v", err)
+    }
+    if ok, err := dialog.Confirm("Delete %d files?", 3); !ok || (err != nil) {
+        t.Errorf("Confirm: got (%t, %v)", ok, err)
+    }
+    if text, ok, err := dialog.Prompt("Login", "Name?", ""); (text != "guest") || !ok || (err != nil) {
+        t.Errorf("Prompt: got (%q, %t, %v)", text, ok, err)
+    }
+    if _, ok, err := dialog.OpenFile("Open", ""); ok || (err != nil) {
+        t.Errorf("OpenFile: got (%t, %v)", ok, err)
+    }
+    if choices, err := dialog.ChooseMany("Fruit", "Pick:", []string{"a", "b", "c"}); !reflect.DeepEqual(choices, []int{0, 2}) {
+        t.Errorf("ChooseMany: got (%v, %v)", choices, err)
+    }
+    if _, err := dialog.Confirm("Again?"); !errors.Is(err, dialog.ErrNoProvider) {
+        t.Errorf("Confirm: expected ErrNoProvider, got %v", err)
+    }
+    if _, err := dialog.Confirm("Unscripted?"); err != dialog.ErrCancelled {
+        t.Errorf("Confirm: expected ErrCancelled, got %v", err)
+    }
+
+    var calls = r.Calls()
+    var kinds = make([]dialog.Kind, 0, len(calls))
+    for _, c := range calls { kinds = append(kinds, c.Kind) }
+
+    var expected = []dialog.Kind{
+        dialog.KindAlert,
+        dialog.KindConfirm,
+        dialog.KindPrompt,
+        dialog.KindOpenFile,
+        dialog.KindChooseMany,
+        dialog.KindConfirm,
+        dialog.KindConfirm,
+    }
+    if !reflect.DeepEqual(kinds, expected) {
+        t.Fatalf("got kinds %v but wanted %v", kinds, expected)
+    }
+
+    if (calls[0].Level != dialog.LevelWarning) || (calls[0].Title != "Warning") {
+        t.Errorf("Warning: unexpected options %+v", calls[0].Options)
+    }
+    if calls[1].Message != "Delete 3 files?" {
+        t.Errorf("Confirm: unexpected message %q", calls[1].Message)
+    }
+
+    r.Reset()
+    if len(r.Calls()) != 0 {
+        t.Errorf("expected no calls after Reset")
+    }
+}
+
+func TestRecorderPick(t *testing.T) {
+    var r = dialogtest.Install(t)
+    var today = time.Date(2021, 12, 25, 0, 0, 0, 0, time.Local)
+    r.Date(today)
+    r.Color(color.RGBA{255, 0, 0, 255})
+    r.Number(30)
+
+    if date, ok, err := dialog.PickDate("Date", "When?"); !date.Equal(today) || !ok || (err != nil) {
+        t.Errorf("PickDate: got (%v, %t, %v)", date, ok, err)
+    }
+    if c, ok, err := dialog.PickColor("Colour"); (c != color.RGBA{255, 0, 0, 255}) || !ok || (err != nil) {
+        t.Errorf("PickColor: got (%v, %t, %v)", c, ok, err)
+    }
+    if n, ok, err := (dialog.Options{}).PickNumber("How many?", 100, 0, 10, 45); (n != 30) || !ok || (err != nil) {
+        t.Errorf("PickNumber: got (%d, %t, %v)", n, ok, err)
+    }
+    if _, ok, err := dialog.PickNumber("Number", "Unscripted?", 0, 10, 1); ok || (err != nil) {
+        t.Errorf("PickNumber: got (%t, %v)", ok, err)
+    }
+
+    var calls = r.Calls()
+    if (len(calls) != 4) || (calls[0].Title != "Date") || (calls[1].Color != color.RGBA{255, 255, 255, 255}) {
+        t.Fatalf("unexpected calls %+v", calls)
+    }
+    if req := calls[2]; (req.Min != 0) || (req.Max != 100) || (req.Step != 10) || (req.Number != 40) {
+        t.Errorf("PickNumber: unexpected request %+v", req)
+    }
+}
+
+func TestInstallRestores(t *testing.T) {
+    var names = func() []string {
+        var names []string
+        for _, p := range dialog.Providers() { names = append(names, p.Name()) }
+        return names
+    }
+
+    var expected = names()
+
+    t.Run("Install", func(t *testing.T) {
+        dialogtest.Install(t)
+        if result := names(); !reflect.DeepEqual(result, []string{dialogtest.Name}) {
+            t.Errorf("got providers %v", result)
+        }
+    })
+
+    // the built-in providers are found again, rather than kept as if
+    // registered by SetProviders, so aren't repeated
+    dialog.Rediscover()
+    if result := names(); !reflect.DeepEqual(result, expected) {
+        t.Errorf("got providers %v but wanted %v", result, expected)
+    }
+}
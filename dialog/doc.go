@@ -23,7 +23,40 @@
 // 
 // Currently, only supports Windows and Linux targets.
 // 
-// On Linux, uses (in order of preference) `zenity`, `xmessage`, or stdio.
+// On Linux, uses (in order of preference) `zenity`, `kdialog`, `yad`,
+// `gxmessage`, `Xdialog`, `xmessage`, a terminal UI, or stdio. On KDE and
+// other Qt-based desktops, `kdialog` is preferred over `zenity`. Without a
+// display, for example over SSH, dialogs are drawn in the terminal, with
+// buttons that can be pressed with the keyboard or clicked. Desktop notifications use
+// `notify-send`.
+// 
+// On Wayland, and in sandboxes such as Flatpak, file dialogs and notifications
+// are instead shown by the xdg-desktop-portal service over D-Bus, so they work
+// even when none of these programs are installed.
+// 
+// Each modal dialog function has a variant with a Context suffix, such as
+// AlertContext, that closes the dialog early (for example, by killing zenity)
+// if its context is done, and returns the context's error. Dialogs shown by
+// several goroutines at once are displayed one at a time.
+// 
+// PickDate, PickColor and PickNumber display a calendar, a colour picker and a
+// slider where the platform has one, and otherwise ask for the value to be
+// typed and checked.
+// 
+// RecoverAndReport can be deferred at the start of main to display a panic,
+// with its stack trace, in an error dialog before the program exits. It can
+// also offer to save the crash report to a file.
+// 
+// Built-in providers are found the first time a dialog is displayed, using
+// $PATH. Available reports what was found, with version numbers, and
+// Rediscover looks again.
+// 
+// Other ways of displaying dialogs can be plugged in by implementing the
+// Provider interface and calling Register. The TAWESOFT_DIALOG environment
+// variable can select providers by name, for example TAWESOFT_DIALOG=stdio to
+// force a headless program to use stdin and stderr. For tests, package
+// dialogtest replaces every provider with one that records each dialog and
+// gives scripted responses.
 // 
 // Example
 // 
@@ -36,6 +69,10 @@
 //     func main() {
 //         dialog.Alert("Hello world!")
 //         dialog.Alert("There are %d lights", 4)
+// 
+//         if ok, _ := dialog.Confirm("Delete %d files?", 3); ok {
+//             dialog.Alert("Deleted!")
+//         }
 //     }
 //
 // FROZEN - PLEASE MIGRATE
@@ -64,6 +101,37 @@
 // etc. please see https://www.tawesoft.co.uk/go and 
 // https://www.tawesoft.co.uk/go/dialog
 //
+//     2026-10-17
+//     
+//         * New Confirm and Question functions return the user's choice
+//         * New Prompt and Password functions for single line text entry
+//         * New OpenFile, OpenFiles, SaveFile and SelectFolder file dialogs
+//         * New AlertE function reports which provider was used and why others failed
+//         * Linux providers return errors instead of printing them to stdout
+//         * New ErrNoProvider, ErrCancelled and ErrUnsupported errors
+//         * New Provider interface, with Register and SetProviders functions
+//         * New TAWESOFT_DIALOG environment variable selects providers by name
+//         * The stdio provider is now also available on Windows
+//         * New kdialog, yad, gxmessage and Xdialog providers on Linux
+//         * New Info, Warning and Error functions display a message with a matching icon
+//         * New Options type sets the title, size, timeout, default button and parent window
+//         * New Notify function displays a non-blocking desktop notification
+//         * New Choose and ChooseMany functions select from a list of items
+//         * New Progress function returns a handle that updates a progress dialog
+//         * New Context variants of each dialog function, such as AlertContext, close a dialog when a context is done
+//         * Dialogs shown by several goroutines at once are displayed one at a time
+//         * New dialogtest package records dialogs and gives scripted responses in tests
+//         * New terminal UI provider on Linux, used when there is no display
+//         * Providers are found lazily with exec.LookPath instead of running "which" at import time
+//         * New Available and Rediscover functions report and refresh the built-in providers found
+//         * New Options.Details adds an expandable details section, or is appended to the message
+//         * zenity messages now escape Pango markup properly, and web links can be clicked
+//         * File dialogs and notifications use xdg-desktop-portal on Wayland
+//         * New RecoverAndReport function displays a panic and its stack trace in a dialog
+//         * Text wrapping keeps line breaks and paragraphs, and measures wide characters, emoji and combining marks correctly
+//         * New PickDate, PickColor and PickNumber functions display a calendar, colour picker or slider
+//         * New NotifyContext and CloseNotifications functions remove notifications early on Windows
+//     
 //     2022-06-29
 //     
 //         * Update dialog to never use a format string with no args
@@ -0,0 +1,64 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+)
+
+var (
+    // ErrNoProvider is returned (wrapped in a *ProviderError) when no
+    // provider was able to display a dialog.
+    ErrNoProvider = errors.New("dialog: no provider was able to display the dialog")
+
+    // ErrCancelled is returned when the user dismissed a dialog without
+    // answering it, in cases where a function has no other way to say so, for
+    // example a Confirm dialog read from a closed stdin.
+    ErrCancelled = errors.New("dialog: cancelled by the user")
+
+    // ErrTimeout is returned when a dialog was dismissed automatically
+    // because Options.Timeout elapsed. errors.Is(ErrTimeout, ErrCancelled)
+    // is true.
+    ErrTimeout error = timeoutError{}
+
+    // ErrUnsupported is recorded for a provider that does not support a
+    // particular kind of dialog at all.
+    ErrUnsupported = errors.New("dialog: not supported by this provider")
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string { return "dialog: timed out" }
+func (timeoutError) Is(target error) bool { return target == ErrCancelled }
+
+// Failure records why a provider failed to display a dialog.
+type Failure struct {
+    Provider string // name of the provider e.g. "zenity"
+    Err error
+}
+
+// Report describes how a dialog was displayed.
+type Report struct {
+    Provider string // name of the provider that displayed the dialog, if any
+    Failures []Failure // providers that failed before that, in the order tried
+}
+
+// ProviderError is the error returned when no provider was able to display a
+// dialog. It wraps ErrNoProvider, so can be tested for with errors.Is.
+type ProviderError struct {
+    Failures []Failure // each provider tried, in order, and why it failed
+}
+
+func (e *ProviderError) Error() string {
+    if len(e.Failures) == 0 { return ErrNoProvider.Error() }
+
+    var reasons = make([]string, 0, len(e.Failures))
+    for _, f := range e.Failures {
+        reasons = append(reasons, fmt.Sprintf("%s: %v", f.Provider, f.Err))
+    }
+    return fmt.Sprintf("%v (%s)", ErrNoProvider, strings.Join(reasons, "; "))
+}
+
+func (e *ProviderError) Unwrap() error {
+    return ErrNoProvider
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+    "fmt"
+    "time"
+
     "tawesoft.co.uk/go/dialog"
 )
 
@@ -13,4 +16,66 @@ func main() {
     dialog.Alert("There are %d lights", 4)
     dialog.Alert("Hello %d world!") // safe because there are no args
     dialog.Alert("Unicode £GBP €EUR")
+
+    dialog.Info("Download complete")
+    dialog.Warning("Disk space is low")
+    dialog.Error("Could not save %s", "example.txt")
+
+    dialog.Options{
+        Level: dialog.LevelError,
+        Details: "goroutine 1 [running]:\nmain.main()\n\t/tmp/main.go:12 +0x1d",
+    }.Alert("The program crashed. See https://example.org/help for help.")
+
+    if ok, err := dialog.Confirm("Delete %d files?", 3); err == nil {
+        dialog.Alert("You answered yes: %t", ok)
+    }
+
+    var answer, err = dialog.Question("Save changes before closing?")
+    if err == nil {
+        dialog.Alert("You answered %s", answer)
+    }
+
+    if name, ok, err := dialog.Prompt("Login", "What is your name?", "guest"); err == nil && ok {
+        dialog.Alert("Hello, %s!", name)
+    }
+
+    if _, ok, err := dialog.Password("Login", "Enter password:"); err == nil && ok {
+        dialog.Alert("Thank you")
+    }
+
+    var profiles = []string{"Minimal", "Standard", "Full"}
+    if index, err := dialog.Choose("Install", "Pick a profile:", profiles); err == nil {
+        dialog.Alert("You picked %s", profiles[index])
+    }
+
+    if progress, err := dialog.Progress("Importing"); err == nil {
+        for i := 0; i <= 100; i += 10 {
+            select {
+                case <-progress.Cancelled():
+                    i = 100
+                case <-time.After(200 * time.Millisecond):
+                    progress.SetText(fmt.Sprintf("Imported %d records", i * 10))
+                    progress.SetPercent(i)
+            }
+        }
+        progress.Close()
+    }
+
+    if date, ok, err := dialog.PickDate("Booking", "Pick a date:"); err == nil && ok {
+        dialog.Alert("You picked %s", date.Format("Monday 2 January 2006"))
+    }
+
+    if c, ok, err := dialog.PickColor("Theme"); err == nil && ok {
+        dialog.Alert("You picked #%02x%02x%02x", c.R, c.G, c.B)
+    }
+
+    if n, ok, err := dialog.PickNumber("Volume", "Set the volume:", 0, 100, 5); err == nil && ok {
+        dialog.Alert("Volume set to %d%%", n)
+    }
+
+    dialog.Notify("Backup", "Backup complete", dialog.Options{Timeout: 5 * time.Second})
+
+    if path, ok, err := dialog.OpenFile("Open image", "", "Images (*.png;*.jpg)", "All files (*)"); err == nil && ok {
+        dialog.Alert("You picked %s", path)
+    }
 }
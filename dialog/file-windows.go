@@ -0,0 +1,229 @@
+// +build windows
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "fmt"
+    "path/filepath"
+    "runtime"
+    "strings"
+    "unsafe"
+
+    "golang.org/x/sys/windows"
+)
+
+var (
+    comdlg32 = windows.NewLazySystemDLL("comdlg32.dll")
+    shell32  = windows.NewLazySystemDLL("shell32.dll")
+    ole32    = windows.NewLazySystemDLL("ole32.dll")
+
+    procGetOpenFileNameW     = comdlg32.NewProc("GetOpenFileNameW")
+    procGetSaveFileNameW     = comdlg32.NewProc("GetSaveFileNameW")
+    procCommDlgExtendedError = comdlg32.NewProc("CommDlgExtendedError")
+    procSHBrowseForFolderW   = shell32.NewProc("SHBrowseForFolderW")
+    procSHGetPathFromIDListW = shell32.NewProc("SHGetPathFromIDListW")
+    procSendMessageW         = user32.NewProc("SendMessageW")
+    procCoTaskMemFree        = ole32.NewProc("CoTaskMemFree")
+)
+
+const (
+    ofnOverwritePrompt   = 0x00000002
+    ofnNoChangeDir       = 0x00000008
+    ofnAllowMultiSelect  = 0x00000200
+    ofnPathMustExist     = 0x00000800
+    ofnFileMustExist     = 0x00001000
+    ofnExplorer          = 0x00080000
+
+    bifReturnOnlyFsDirs  = 0x00000001
+    bifNewDialogStyle    = 0x00000040
+
+    bffmInitialized      = 1
+    bffmSetSelectionW    = 0x0400 + 103 // WM_USER + 103
+
+    maxFileBuffer        = 32 * 1024
+)
+
+// OPENFILENAMEW
+type openFileName struct {
+    structSize    uint32
+    owner         uintptr
+    instance      uintptr
+    filter        *uint16
+    customFilter  *uint16
+    maxCustFilter uint32
+    filterIndex   uint32
+    file          *uint16
+    maxFile       uint32
+    fileTitle     *uint16
+    maxFileTitle  uint32
+    initialDir    *uint16
+    title         *uint16
+    flags         uint32
+    fileOffset    uint16
+    fileExtension uint16
+    defExt        *uint16
+    custData      uintptr
+    hook          uintptr
+    templateName  *uint16
+    reserved1     uintptr
+    reserved2     uint32
+    flagsEx       uint32
+}
+
+// BROWSEINFOW
+type browseInfo struct {
+    owner       uintptr
+    root        uintptr
+    displayName *uint16
+    title       *uint16
+    flags       uint32
+    callback    uintptr
+    lParam      uintptr
+    image       int32
+}
+
+// windowsFilter returns filters in the form "Name\0*.a;*.b\0...\0\0". It
+// returns an error if a name or pattern contains a null.
+func windowsFilter(filters []Filter) ([]uint16, error) {
+    if len(filters) == 0 { return nil, nil }
+
+    var buf = make([]uint16, 0, 64)
+    for _, f := range filters {
+        var name, err = windows.UTF16FromString(f.Name)
+        if err != nil { return nil, err }
+        var patterns []uint16
+        patterns, err = windows.UTF16FromString(strings.Join(f.Patterns, ";"))
+        if err != nil { return nil, err }
+        buf = append(buf, name...)
+        buf = append(buf, patterns...)
+    }
+    return append(buf, 0), nil
+}
+
+// splitMultiSelect splits the result of a multiple selection, which is either
+// a single path, or a directory followed by each filename, separated by nulls.
+func splitMultiSelect(buf []uint16) []string {
+    var parts = make([]string, 0, 1)
+    for start, i := 0, 0; i < len(buf); i++ {
+        if buf[i] != 0 { continue }
+        if i == start { break } // double null
+        parts = append(parts, windows.UTF16ToString(buf[start:i]))
+        start = i + 1
+    }
+
+    if len(parts) <= 1 { return parts }
+
+    var results = make([]string, 0, len(parts) - 1)
+    for _, name := range parts[1:] {
+        results = append(results, filepath.Join(parts[0], name))
+    }
+    return results
+}
+
+func windowsFile(req *Request) ([]string, bool, error) {
+    if req.Kind == KindSelectFolder { return selectFolder(req) }
+
+    var wfilter, err = windowsFilter(req.Filters)
+    if err != nil { return nil, false, err }
+
+    var wdefault []uint16
+    wdefault, err = windows.UTF16FromString(req.Default)
+    if err != nil { return nil, false, err }
+
+    var wtitle *uint16
+    wtitle, err = windows.UTF16PtrFromString(req.Title)
+    if err != nil { return nil, false, err }
+
+    var buf = make([]uint16, maxFileBuffer)
+    copy(buf[0:len(buf)-1], wdefault)
+
+    var ofn = openFileName{
+        owner:   req.Parent,
+        file:    &buf[0],
+        maxFile: uint32(len(buf)),
+        title:   wtitle,
+        flags:   ofnExplorer | ofnNoChangeDir | ofnPathMustExist,
+    }
+    ofn.structSize = uint32(unsafe.Sizeof(ofn))
+
+    if len(wfilter) > 0 {
+        ofn.filter = &wfilter[0]
+        ofn.filterIndex = 1
+    }
+    if len(req.Directory) > 0 {
+        ofn.initialDir, err = windows.UTF16PtrFromString(req.Directory)
+        if err != nil { return nil, false, err }
+    }
+
+    var proc = procGetOpenFileNameW
+    switch req.Kind {
+        case KindOpenFile:  ofn.flags |= ofnFileMustExist
+        case KindOpenFiles: ofn.flags |= ofnFileMustExist | ofnAllowMultiSelect
+        case KindSaveFile:
+            ofn.flags |= ofnOverwritePrompt
+            proc = procGetSaveFileNameW
+    }
+
+    var result, _, _ = proc.Call(uintptr(unsafe.Pointer(&ofn)))
+    runtime.KeepAlive(wfilter)
+    runtime.KeepAlive(wtitle)
+
+    if result == 0 {
+        var code, _, _ = procCommDlgExtendedError.Call()
+        if code == 0 { return nil, false, nil } // cancelled
+        return nil, false, fmt.Errorf("CommDlgExtendedError: 0x%04x", code)
+    }
+
+    if req.Kind == KindOpenFiles {
+        return splitMultiSelect(buf), true, nil
+    }
+    return []string{windows.UTF16ToString(buf)}, true, nil
+}
+
+var browseCallback = windows.NewCallback(func(hwnd windows.HWND, msg uintptr, lparam uintptr, data uintptr) uintptr {
+    if (msg == bffmInitialized) && (data != 0) {
+        procSendMessageW.Call(uintptr(hwnd), bffmSetSelectionW, 1, data)
+    }
+    return 0
+})
+
+func selectFolder(req *Request) ([]string, bool, error) {
+    runtime.LockOSThread()
+    defer runtime.UnlockOSThread()
+
+    // the new dialog style requires COM
+    if err := windows.CoInitializeEx(0, windows.COINIT_APARTMENTTHREADED); err == nil {
+        defer windows.CoUninitialize()
+    }
+
+    var wtitle, err = windows.UTF16PtrFromString(req.Title)
+    if err != nil { return nil, false, err }
+
+    var wdirectory *uint16
+    if len(req.Directory) > 0 {
+        wdirectory, err = windows.UTF16PtrFromString(req.Directory)
+        if err != nil { return nil, false, err }
+    }
+
+    var displayName = make([]uint16, windows.MAX_PATH)
+    var info = browseInfo{
+        owner:       req.Parent,
+        displayName: &displayName[0],
+        title:       wtitle,
+        flags:       bifReturnOnlyFsDirs | bifNewDialogStyle,
+        callback:    browseCallback,
+        lParam:      uintptr(unsafe.Pointer(wdirectory)),
+    }
+
+    var pidl, _, _ = procSHBrowseForFolderW.Call(uintptr(unsafe.Pointer(&info)))
+    runtime.KeepAlive(wdirectory)
+    runtime.KeepAlive(wtitle)
+    if pidl == 0 { return nil, false, nil } // cancelled
+    defer procCoTaskMemFree.Call(pidl)
+
+    var path = make([]uint16, windows.MAX_PATH)
+    var ok, _, lastErr = procSHGetPathFromIDListW.Call(pidl, uintptr(unsafe.Pointer(&path[0])))
+    if ok == 0 { return nil, false, callError("SHGetPathFromIDListW", lastErr) }
+
+    return []string{windows.UTF16ToString(path)}, true, nil
+}
@@ -0,0 +1,163 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "errors"
+    "strings"
+)
+
+// Filter restricts the files shown by a file dialog to those matching any of
+// a list of patterns, such as "*.png".
+type Filter struct {
+    Name string // e.g. "Images"
+    Patterns []string // e.g. "*.png", "*.jpg"
+}
+
+// parseFilter parses a filter in the form "Name (*.a;*.b)". Patterns may be
+// separated by semicolons or spaces. A filter with no name, such as "*.txt",
+// uses its patterns as its name.
+func parseFilter(s string) Filter {
+    s = strings.TrimSpace(s)
+
+    var name, patterns = "", s
+    if strings.HasSuffix(s, ")") {
+        if index := strings.LastIndex(s, "("); index >= 0 {
+            name = strings.TrimSpace(s[0:index])
+            patterns = s[index+1:len(s)-1]
+        }
+    }
+
+    var f = Filter{
+        Patterns: strings.FieldsFunc(patterns, func(r rune) bool {
+            return (r == ';') || (r == ' ')
+        }),
+    }
+
+    if len(name) == 0 {
+        f.Name = strings.Join(f.Patterns, " ")
+    } else {
+        f.Name = name
+    }
+
+    return f
+}
+
+func parseFilters(filters []string) []Filter {
+    var results = make([]Filter, 0, len(filters))
+    for _, s := range filters {
+        var f = parseFilter(s)
+        if len(f.Patterns) == 0 { continue }
+        results = append(results, f)
+    }
+    return results
+}
+
+// OpenFile displays a modal dialog for selecting a single existing file,
+// starting in directory (if not empty). It returns the path to the selected
+// file and true, or false if the user cancelled the dialog.
+//
+// Each filter, if any, restricts the files shown to the user and takes the
+// form "Name (*.a;*.b)" e.g. "Images (*.png;*.jpg)" or just "*.txt".
+//
+// An error is returned if the dialog could not be displayed at all.
+func OpenFile(title string, directory string, filters ...string) (string, bool, error) {
+    return Options{Title: title}.OpenFile(directory, filters...)
+}
+
+// OpenFiles is like OpenFile, but the user may select several files.
+func OpenFiles(title string, directory string, filters ...string) ([]string, bool, error) {
+    return Options{Title: title}.OpenFiles(directory, filters...)
+}
+
+// SaveFile displays a modal dialog for choosing a path to save a file,
+// starting in directory (if not empty) with a default filename (if not
+// empty). It returns the chosen path and true, or false if the user cancelled
+// the dialog. Where supported, the user is asked to confirm before choosing
+// an existing file.
+//
+// Filters are the same as for OpenFile.
+//
+// An error is returned if the dialog could not be displayed at all.
+func SaveFile(title string, directory string, filename string, filters ...string) (string, bool, error) {
+    return Options{Title: title}.SaveFile(directory, filename, filters...)
+}
+
+// SelectFolder displays a modal dialog for selecting an existing directory,
+// starting in directory (if not empty). It returns the path to the selected
+// directory and true, or false if the user cancelled the dialog.
+//
+// An error is returned if the dialog could not be displayed at all.
+func SelectFolder(title string, directory string) (string, bool, error) {
+    return Options{Title: title}.SelectFolder(directory)
+}
+
+// OpenFile displays a modal dialog for selecting a single existing file. See
+// the OpenFile function.
+func (o Options) OpenFile(directory string, filters ...string) (string, bool, error) {
+    return o.OpenFileContext(context.Background(), directory, filters...)
+}
+
+// OpenFileContext is like OpenFile, but closes the dialog early if ctx is
+// done.
+func (o Options) OpenFileContext(ctx context.Context, directory string, filters ...string) (string, bool, error) {
+    var req = o.request(KindOpenFile, "")
+    req.Directory = directory
+    req.Filters = parseFilters(filters)
+    return showFile(ctx, req)
+}
+
+// OpenFiles is like OpenFile, but the user may select several files.
+func (o Options) OpenFiles(directory string, filters ...string) ([]string, bool, error) {
+    return o.OpenFilesContext(context.Background(), directory, filters...)
+}
+
+// OpenFilesContext is like OpenFiles, but closes the dialog early if ctx is
+// done.
+func (o Options) OpenFilesContext(ctx context.Context, directory string, filters ...string) ([]string, bool, error) {
+    var req = o.request(KindOpenFiles, "")
+    req.Directory = directory
+    req.Filters = parseFilters(filters)
+
+    var response, _, err = show(ctx, req)
+    if errors.Is(err, ErrCancelled) { return nil, false, nil }
+    if (err != nil) || (len(response.Paths) == 0) { return nil, false, err }
+    return response.Paths, true, nil
+}
+
+// SaveFile displays a modal dialog for choosing a path to save a file. See
+// the SaveFile function.
+func (o Options) SaveFile(directory string, filename string, filters ...string) (string, bool, error) {
+    return o.SaveFileContext(context.Background(), directory, filename, filters...)
+}
+
+// SaveFileContext is like SaveFile, but closes the dialog early if ctx is
+// done.
+func (o Options) SaveFileContext(ctx context.Context, directory string, filename string, filters ...string) (string, bool, error) {
+    var req = o.request(KindSaveFile, "")
+    req.Directory = directory
+    req.Default = filename
+    req.Filters = parseFilters(filters)
+    return showFile(ctx, req)
+}
+
+// SelectFolder displays a modal dialog for selecting an existing directory.
+// See the SelectFolder function.
+func (o Options) SelectFolder(directory string) (string, bool, error) {
+    return o.SelectFolderContext(context.Background(), directory)
+}
+
+// SelectFolderContext is like SelectFolder, but closes the dialog early if
+// ctx is done.
+func (o Options) SelectFolderContext(ctx context.Context, directory string) (string, bool, error) {
+    var req = o.request(KindSelectFolder, "")
+    req.Directory = directory
+    return showFile(ctx, req)
+}
+
+// showFile shows a file dialog that returns a single path.
+func showFile(ctx context.Context, req *Request) (string, bool, error) {
+    var response, _, err = show(ctx, req)
+    if errors.Is(err, ErrCancelled) { return "", false, nil }
+    if (err != nil) || (len(response.Paths) == 0) { return "", false, err }
+    return response.Paths[0], true, nil
+}
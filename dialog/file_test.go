@@ -0,0 +1,33 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestParseFilter(t *testing.T) {
+    var tests = []struct{
+        input string
+        expected Filter
+    }{
+        {"Images (*.png;*.jpg)",    Filter{"Images", []string{"*.png", "*.jpg"}}},
+        {"Images (*.png *.jpg)",    Filter{"Images", []string{"*.png", "*.jpg"}}},
+        {"  Text files (*.txt)  ",  Filter{"Text files", []string{"*.txt"}}},
+        {"*.txt",                   Filter{"*.txt", []string{"*.txt"}}},
+        {"*.c;*.h",                 Filter{"*.c *.h", []string{"*.c", "*.h"}}},
+        {"(*.go)",                  Filter{"*.go", []string{"*.go"}}},
+        {"Archive (old) (*.tar)",   Filter{"Archive (old)", []string{"*.tar"}}},
+    }
+
+    for index, test := range tests {
+        var result = parseFilter(test.input)
+        if !reflect.DeepEqual(result, test.expected) {
+            t.Errorf("Test %d: parseFilter(%q): got %+v but wanted %+v",
+                index, test.input, result, test.expected)
+        }
+    }
+
+    if len(parseFilters([]string{"", "  ", "*.txt"})) != 1 {
+        t.Errorf("parseFilters: expected empty filters to be skipped")
+    }
+}
@@ -0,0 +1,10 @@
+// Package hooks gives package dialogtest access to parts of package dialog
+// that aren't exported.
+package hooks // import "tawesoft.co.uk/go/dialog/internal/hooks"
+
+// SaveProviders is set by package dialog. It saves the registered providers
+// and the dialog.EnvProviders environment variable, and returns a function
+// that restores them exactly. Unlike dialog.SetProviders, restoring them
+// doesn't stop the built-in providers being discovered when first needed, or
+// again by dialog.Rediscover.
+var SaveProviders func() (restore func())
@@ -0,0 +1,151 @@
+// +build linux
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "fmt"
+    "os/exec"
+    "strconv"
+    "strings"
+)
+
+// kdialogProvider uses kdialog, the KDE equivalent of zenity.
+type kdialogProvider struct {
+    command string
+}
+
+func (p kdialogProvider) Name() string { return "kdialog" }
+
+func (p kdialogProvider) Show(ctx context.Context, req *Request) (Response, error) {
+    var args = []string{"--title", req.Title}
+    if (req.Width > 0) && (req.Height > 0) {
+        args = append(args, "--geometry", fmt.Sprintf("%dx%d", req.Width, req.Height))
+    }
+    if req.Parent != 0 {
+        args = append(args, "--attach", fmt.Sprintf("%d", req.Parent))
+    }
+
+    switch req.Kind {
+        case KindAlert:
+            // warnings and errors can have an expandable details section
+            var kind, message = "--msgbox", []string{req.PlainMessage()}
+            switch req.Level {
+                case LevelWarning: kind = "--sorry"
+                case LevelError:   kind = "--error"
+            }
+            if (kind != "--msgbox") && (len(req.Details) > 0) {
+                kind, message = "--detailed" + kind[2:], []string{req.Message, req.Details}
+            }
+
+            var err = exec.CommandContext(ctx, p.command, append(append(args, kind), message...)...).Run()
+            switch code := exitCode(err); code {
+                case 0, 1: return Response{}, nil // "OK", or the dialog was closed
+                case -1:   return Response{}, err
+                default:   return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+            }
+
+        case KindConfirm, KindQuestion:
+            var flag = "--yesno"
+            if req.Kind == KindQuestion { flag = "--yesnocancel" }
+
+            var err = exec.CommandContext(ctx, p.command, append(args, flag, req.PlainMessage())...).Run()
+            switch code := exitCode(err); code {
+                case 0:  return Response{Answer: Yes}, nil
+                case 1:  return Response{Answer: No}, nil
+                case 2:  return Response{Answer: Cancel}, nil
+                case -1: return Response{}, err
+                default: return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+            }
+
+        case KindPrompt:
+            args = append(args, "--inputbox", req.Message, req.Default)
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            return p.result(err, func() Response {
+                return Response{Text: strings.TrimSuffix(string(out), "\n")}
+            })
+
+        case KindPassword:
+            args = append(args, "--password", req.Message)
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            return p.result(err, func() Response {
+                return Response{Text: strings.TrimSuffix(string(out), "\n")}
+            })
+
+        case KindOpenFile, KindOpenFiles, KindSaveFile, KindSelectFolder:
+            var start = startPath(req)
+            if len(start) == 0 { start = "." }
+
+            switch req.Kind {
+                case KindOpenFile:     args = append(args, "--getopenfilename", start, kdialogFilter(req.Filters))
+                case KindOpenFiles:    args = append(args, "--getopenfilename", start, kdialogFilter(req.Filters),
+                                           "--multiple", "--separate-output")
+                case KindSaveFile:     args = append(args, "--getsavefilename", start, kdialogFilter(req.Filters))
+                case KindSelectFolder: args = append(args, "--getexistingdirectory", start)
+            }
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            return p.result(err, func() Response {
+                return Response{Paths: strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")}
+            })
+
+        case KindChoose, KindChooseMany:
+            // each item is tagged with its number, counting from 1
+            if req.Kind == KindChooseMany {
+                args = append(args, "--checklist", req.Message, "--separate-output")
+            } else {
+                args = append(args, "--menu", req.Message)
+            }
+
+            for i, item := range req.Items {
+                args = append(args, strconv.Itoa(i + 1), item)
+                if req.Kind == KindChooseMany { args = append(args, "off") }
+            }
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            if err != nil { return p.result(err, nil) }
+            return choiceResponse(p.command, string(out), len(req.Items))
+
+        case KindPickDate, KindPickColor, KindPickNumber:
+            // kdialog's calendar and slider have no initial value
+            switch req.Kind {
+                case KindPickDate:   args = append(args, "--calendar", req.Message)
+                case KindPickColor:  args = append(args, "--getcolor", "--default", formatColor(req.Color))
+                case KindPickNumber: args = append(args, "--slider", req.Message,
+                                         strconv.Itoa(req.Min), strconv.Itoa(req.Max), strconv.Itoa(req.Step))
+            }
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            if err != nil { return p.result(err, nil) }
+            if req.Kind == KindPickDate {
+                // the calendar prints a date in Qt's default form, such as
+                // "Sat Dec 25 2021", in English or, in some versions, the
+                // language of the user, which can't be understood
+                return pickResponse(p.command, req, isoDate(string(out), "Mon Jan 2 2006"))
+            }
+            return pickResponse(p.command, req, string(out))
+
+        default:
+            return Response{}, ErrUnsupported
+    }
+}
+
+// kdialogFilter returns filters in the form "*.a *.b|Name", one per line.
+func kdialogFilter(filters []Filter) string {
+    var lines = make([]string, 0, len(filters))
+    for _, f := range filters {
+        lines = append(lines, strings.Join(f.Patterns, " ")+"|"+f.Name)
+    }
+    return strings.Join(lines, "\n")
+}
+
+// result interprets the exit status of a kdialog dialog that returns its
+// result on stdout, where an exit status of 1 means it was cancelled.
+func (p kdialogProvider) result(err error, f func() Response) (Response, error) {
+    switch code := exitCode(err); code {
+        case 0:  return f(), nil
+        case 1:  return Response{}, ErrCancelled
+        case -1: return Response{}, err
+        default: return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+    }
+}
@@ -0,0 +1,47 @@
+// +build linux
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "os"
+    "os/exec"
+    "path/filepath"
+)
+
+// notifySendProvider uses notify-send from libnotify, which sends a message
+// to the org.freedesktop.Notifications D-Bus service. It only supports
+// notifications.
+type notifySendProvider struct {
+    command string
+}
+
+func (p notifySendProvider) Name() string { return "notify-send" }
+
+func (p notifySendProvider) Show(ctx context.Context, req *Request) (Response, error) {
+    if req.Kind != KindNotify { return Response{}, ErrUnsupported }
+
+    var icon, urgency = "dialog-information", "normal"
+    switch req.Level {
+        case LevelWarning: icon = "dialog-warning"
+        case LevelError:   icon, urgency = "dialog-error", "critical"
+    }
+
+    var args = []string{
+        "--app-name=" + filepath.Base(os.Args[0]),
+        "--icon=" + icon,
+        "--urgency=" + urgency,
+    }
+    if req.Timeout > 0 {
+        args = append(args, fmt.Sprintf("--expire-time=%d", req.Timeout.Milliseconds()))
+    }
+    args = append(args, "--", req.Title, req.Message)
+
+    var out, err = exec.CommandContext(ctx, p.command, args...).CombinedOutput()
+    if (exitCode(err) > 0) && (len(out) > 0) {
+        return Response{}, fmt.Errorf("%s: %s", p.command, bytes.TrimSpace(out))
+    }
+    return Response{}, err
+}
@@ -0,0 +1,173 @@
+// +build windows
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "errors"
+    "context"
+    "runtime"
+    "sync"
+    "time"
+    "unsafe"
+
+    "golang.org/x/sys/windows"
+)
+
+var (
+    procShellNotifyIconW = shell32.NewProc("Shell_NotifyIconW")
+    procCreateWindowExW  = user32.NewProc("CreateWindowExW")
+    procDestroyWindow    = user32.NewProc("DestroyWindow")
+    procLoadIconW        = user32.NewProc("LoadIconW")
+)
+
+const (
+    nimAdd    = 0
+    nimDelete = 2
+
+    nifIcon   = 0x00000002
+    nifTip    = 0x00000004
+    nifInfo   = 0x00000010
+
+    niifInfo    = 0x00000001
+    niifWarning = 0x00000002
+    niifError   = 0x00000003
+
+    idiInformation = 32516
+    idiWarning     = 32515
+    idiError       = 32513
+
+    hwndMessage = ^uintptr(2) // HWND_MESSAGE, i.e. -3
+
+    // how long a notification is shown for if Options.Timeout is zero
+    defaultNotifyTimeout = 10 * time.Second
+)
+
+// NOTIFYICONDATAW
+type notifyIconData struct {
+    size            uint32
+    wnd             uintptr
+    id              uint32
+    flags           uint32
+    callbackMessage uint32
+    icon            uintptr
+    tip             [128]uint16
+    state           uint32
+    stateMask       uint32
+    info            [256]uint16
+    timeout         uint32
+    infoTitle       [64]uint16
+    infoFlags       uint32
+    guidItem        windows.GUID
+    balloonIcon     uintptr
+}
+
+// copyWideChar copies s into a fixed size buffer, truncating it if necessary
+// so that it is always null-terminated.
+func copyWideChar(dest []uint16, s string) {
+    var src = toWideChar(s)
+    if len(src) > len(dest) { src = src[0:len(dest)] }
+    copy(dest, src)
+    dest[len(dest)-1] = 0
+}
+
+// Icons that are still shown when the process exits stay in the notification
+// area until the user hovers over them, so windowsCloseNotifications can
+// remove them early.
+var (
+    notifyMutex   sync.Mutex
+    notifyClosing = make(chan struct{}) // closed to remove every icon
+    notifyShown   sync.WaitGroup        // counts icons not yet removed
+)
+
+// windowsNotify adds an icon to the notification area that displays a
+// balloon message, and returns once it has been added. The icon is removed
+// again after a delay, when ctx is done, or by windowsCloseNotifications,
+// whichever is first.
+//
+// The icon belongs to a hidden message-only window, which must be created and
+// destroyed on the same thread, so this happens on a new goroutine locked to
+// its own OS thread.
+func windowsNotify(ctx context.Context, req *Request) error {
+    var icon, infoFlags uintptr = idiInformation, niifInfo
+    switch req.Level {
+        case LevelWarning: icon, infoFlags = idiWarning, niifWarning
+        case LevelError:   icon, infoFlags = idiError, niifError
+    }
+
+    var timeout = req.Timeout
+    if timeout <= 0 { timeout = defaultNotifyTimeout }
+
+    var result = make(chan error, 1)
+
+    notifyMutex.Lock()
+    var closing = notifyClosing
+    notifyShown.Add(1)
+    notifyMutex.Unlock()
+
+    go func() {
+        defer notifyShown.Done()
+        runtime.LockOSThread()
+        defer runtime.UnlockOSThread()
+
+        var className = toWideChar("STATIC")
+        var hwnd, _, err = procCreateWindowExW.Call(
+            0,
+            uintptr(unsafe.Pointer(&className[0])),
+            0,
+            0,
+            0, 0, 0, 0,
+            hwndMessage,
+            0, 0, 0,
+        )
+        if hwnd == 0 {
+            result <- callError("CreateWindowExW", err)
+            return
+        }
+        defer procDestroyWindow.Call(hwnd)
+
+        var hicon, _, _ = procLoadIconW.Call(0, icon)
+
+        var data = notifyIconData{
+            wnd:       hwnd,
+            id:        1,
+            flags:     nifIcon | nifTip | nifInfo,
+            icon:      hicon,
+            timeout:   uint32(timeout.Milliseconds()),
+            infoFlags: uint32(infoFlags),
+        }
+        data.size = uint32(unsafe.Sizeof(data))
+        copyWideChar(data.tip[:], req.Title)
+        copyWideChar(data.infoTitle[:], req.Title)
+        copyWideChar(data.info[:], req.Message)
+
+        var ok, _, _ = procShellNotifyIconW.Call(nimAdd, uintptr(unsafe.Pointer(&data)))
+        if ok == 0 {
+            result <- errors.New("Shell_NotifyIconW: failed to add icon")
+            return
+        }
+        result <- nil
+
+        var timer = time.NewTimer(timeout)
+        defer timer.Stop()
+
+        select {
+            case <-timer.C:
+            case <-ctx.Done():
+            case <-closing:
+        }
+        procShellNotifyIconW.Call(nimDelete, uintptr(unsafe.Pointer(&data)))
+    }()
+
+    return <-result
+}
+
+// windowsCloseNotifications removes every icon added by windowsNotify, and
+// returns once they have all been removed.
+func windowsCloseNotifications() {
+    notifyMutex.Lock()
+    close(notifyClosing)
+    notifyClosing = make(chan struct{})
+    notifyMutex.Unlock()
+
+    notifyShown.Wait()
+}
@@ -0,0 +1,45 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+)
+
+// Notify displays a desktop notification with a title and a body of text,
+// using options.Level to choose an icon. Unlike the other dialogs, Notify
+// does not wait for the user to respond: it returns as soon as the
+// notification has been shown.
+//
+// If options.Timeout is not zero, it is how long the notification stays on
+// the screen, where supported. Otherwise, this is up to the platform.
+//
+// On Linux, this uses notify-send, and on Windows, a notification area icon
+// with a balloon message. Otherwise, the notification is written to stderr.
+//
+// On Windows, a notification that is still shown when the program exits stays
+// in the notification area until the user hovers over it, so call
+// CloseNotifications before exiting.
+//
+// If no provider was able to display the notification, the returned error is
+// a *ProviderError that wraps ErrNoProvider.
+func Notify(title string, body string, options Options) error {
+    return NotifyContext(context.Background(), title, body, options)
+}
+
+// NotifyContext is like Notify, but also removes the notification early when
+// ctx is done, where supported.
+func NotifyContext(ctx context.Context, title string, body string, options Options) error {
+    if len(title) > 0 { options.Title = title }
+    var _, _, err = show(ctx, options.request(KindNotify, body))
+    return err
+}
+
+// CloseNotifications removes any notifications shown by Notify that are still
+// on the screen, where supported, and returns once they have been removed.
+// Call it before the program exits.
+func CloseNotifications() {
+    closeNotifications()
+}
+
+// closeNotifications is replaced by each platform that can remove a
+// notification early.
+var closeNotifications = func() {}
@@ -0,0 +1,162 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "errors"
+    "time"
+)
+
+// Options customise how a dialog is displayed. The zero value uses the
+// default for each option. Each provider honours these options where it can,
+// and silently ignores them where it can't.
+//
+// Each method of Options displays a dialog in the same way as the package
+// function of the same name, but with these options applied.
+type Options struct {
+    // Title overrides the default title of a dialog e.g. "Alert".
+    Title string
+
+    // Level is the severity of a message displayed by Alert.
+    Level Level
+
+    // Details is optional extra text for an Alert, Confirm or Question
+    // dialog, such as a stack trace or a log, that is displayed in an
+    // expandable section or a separate window where supported, and otherwise
+    // after the message.
+    Details string
+
+    // Width and Height are the preferred size of a dialog, in pixels.
+    Width, Height int
+
+    // Timeout, if not zero, automatically dismisses a dialog after a delay.
+    // A dialog that times out returns ErrTimeout, which is treated as if the
+    // user had cancelled the dialog.
+    Timeout time.Duration
+
+    // DefaultButton is the button selected by default in a Confirm or
+    // Question dialog, if not zero. Otherwise, this is Yes.
+    DefaultButton Answer
+
+    // Parent is a native handle to a parent window, so that a dialog can be
+    // modal to it: a HWND on Windows or an X11 window ID on Linux.
+    Parent uintptr
+}
+
+// defaultTitle returns the title for a kind of dialog if Options.Title is
+// empty.
+func defaultTitle(kind Kind, level Level) string {
+    if kind == KindAlert {
+        switch level {
+            case LevelInfo:    return "Information"
+            case LevelWarning: return "Warning"
+            case LevelError:   return "Error"
+        }
+    }
+
+    switch kind {
+        case KindOpenFile:     return "Open File"
+        case KindOpenFiles:    return "Open Files"
+        case KindSaveFile:     return "Save File"
+        case KindSelectFolder: return "Select Folder"
+        case KindNotify:       return "Notification"
+        case KindChoose:       return "Choose"
+        case KindChooseMany:   return "Choose"
+        case KindPickDate:     return "Pick Date"
+        case KindPickColor:    return "Pick Color"
+        case KindPickNumber:   return "Pick Number"
+        default:               return kind.String()
+    }
+}
+
+// request returns a new Request for a kind of dialog with these options.
+func (o Options) request(kind Kind, message string) *Request {
+    var req = &Request{
+        Kind:    kind,
+        Message: message,
+        Options: o,
+    }
+    if len(req.Title) == 0 { req.Title = defaultTitle(kind, o.Level) }
+    return req
+}
+
+// Alert displays a modal message box with message. The message string can
+// be a printf-style format string for an optional sequence of additional
+// arguments of any type.
+//
+// If no provider was able to display the message, the returned error is a
+// *ProviderError that wraps ErrNoProvider.
+func (o Options) Alert(message string, args...interface{}) error {
+    return o.AlertContext(context.Background(), message, args...)
+}
+
+// AlertContext is like Alert, but closes the dialog early if ctx is done.
+func (o Options) AlertContext(ctx context.Context, message string, args...interface{}) error {
+    var _, err = o.alert(ctx, format(message, args))
+    return err
+}
+
+func (o Options) alert(ctx context.Context, message string) (Report, error) {
+    var _, report, err = show(ctx, o.request(KindAlert, message))
+    if errors.Is(err, ErrTimeout) { err = nil } // an alert is allowed to time out
+    return report, err
+}
+
+// Confirm displays a modal message box with message and "Yes" and "No"
+// buttons, and returns true iff the user pressed "Yes". See the Confirm
+// function.
+func (o Options) Confirm(message string, args...interface{}) (bool, error) {
+    return o.ConfirmContext(context.Background(), message, args...)
+}
+
+// ConfirmContext is like Confirm, but closes the dialog early if ctx is done.
+func (o Options) ConfirmContext(ctx context.Context, message string, args...interface{}) (bool, error) {
+    var response, _, err = show(ctx, o.request(KindConfirm, format(message, args)))
+    return response.Answer == Yes, err
+}
+
+// Question displays a modal message box with message and "Yes", "No" and
+// "Cancel" buttons, and returns the button pressed by the user. See the
+// Question function.
+func (o Options) Question(message string, args...interface{}) (Answer, error) {
+    return o.QuestionContext(context.Background(), message, args...)
+}
+
+// QuestionContext is like Question, but closes the dialog early if ctx is
+// done.
+func (o Options) QuestionContext(ctx context.Context, message string, args...interface{}) (Answer, error) {
+    var response, _, err = show(ctx, o.request(KindQuestion, format(message, args)))
+    if errors.Is(err, ErrCancelled) { return Cancel, nil }
+    return response.Answer, err
+}
+
+// Prompt displays a modal dialog with a message and a single line text entry
+// initially containing defaultValue. See the Prompt function.
+func (o Options) Prompt(message string, defaultValue string) (string, bool, error) {
+    return o.PromptContext(context.Background(), message, defaultValue)
+}
+
+// PromptContext is like Prompt, but closes the dialog early if ctx is done.
+func (o Options) PromptContext(ctx context.Context, message string, defaultValue string) (string, bool, error) {
+    var req = o.request(KindPrompt, message)
+    req.Default = defaultValue
+    return showPrompt(ctx, req)
+}
+
+// Password is like Prompt, but the text entered by the user is masked and
+// there is no default value.
+func (o Options) Password(message string) (string, bool, error) {
+    return o.PasswordContext(context.Background(), message)
+}
+
+// PasswordContext is like Password, but closes the dialog early if ctx is
+// done.
+func (o Options) PasswordContext(ctx context.Context, message string) (string, bool, error) {
+    return showPrompt(ctx, o.request(KindPassword, message))
+}
+
+func showPrompt(ctx context.Context, req *Request) (string, bool, error) {
+    var response, _, err = show(ctx, req)
+    if errors.Is(err, ErrCancelled) { return "", false, nil }
+    if err != nil { return "", false, err }
+    return response.Text, true, nil
+}
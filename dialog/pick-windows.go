@@ -0,0 +1,88 @@
+// +build windows
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "fmt"
+    "image/color"
+    "unsafe"
+
+    "golang.org/x/sys/windows"
+)
+
+var procChooseColorW = comdlg32.NewProc("ChooseColorW")
+
+const (
+    ccRGBInit  = 0x00000001
+    ccFullOpen = 0x00000002
+    ccAnyColor = 0x00000100
+)
+
+// CHOOSECOLORW
+type chooseColor struct {
+    structSize   uint32
+    owner        uintptr
+    instance     uintptr
+    rgbResult    uint32 // COLORREF, 0x00bbggrr
+    custColors   *[16]uint32
+    flags        uint32
+    custData     uintptr
+    hook         uintptr
+    templateName *uint16
+}
+
+// customColors are the custom colours in a colour dialog, which the user can
+// change, and are remembered between dialogs.
+var customColors [16]uint32
+
+func windowsColor(req *Request) (color.RGBA, bool, error) {
+    var c = req.Color
+    var cc = chooseColor{
+        owner:      req.Parent,
+        rgbResult:  uint32(c.R) | (uint32(c.G) << 8) | (uint32(c.B) << 16),
+        custColors: &customColors,
+        flags:      ccRGBInit | ccFullOpen | ccAnyColor,
+    }
+    cc.structSize = uint32(unsafe.Sizeof(cc))
+
+    var result, _, _ = procChooseColorW.Call(uintptr(unsafe.Pointer(&cc)))
+    if result == 0 {
+        var code, _, _ = procCommDlgExtendedError.Call()
+        if code == 0 { return color.RGBA{}, false, nil } // cancelled
+        return color.RGBA{}, false, fmt.Errorf("CommDlgExtendedError: 0x%04x", code)
+    }
+
+    var rgb = cc.rgbResult
+    return color.RGBA{uint8(rgb), uint8(rgb >> 8), uint8(rgb >> 16), 255}, true, nil
+}
+
+// windowsPickText asks for a date or a number to be typed in a prompt, as
+// there is no standard dialog for either. It asks again, after explaining
+// the problem, until it gets a valid answer.
+func windowsPickText(req *Request) (Response, error) {
+    var initial, hint = pickText(req)
+
+    var prompt = *req
+    prompt.Kind = KindPrompt
+    prompt.Message = fmt.Sprintf("%s (%s)", req.Message, hint)
+    prompt.Default = initial
+
+    for {
+        var text, ok, err = windowsPrompt(&prompt)
+        if err != nil { return Response{}, err }
+        if !ok { return Response{}, ErrCancelled }
+
+        var response, perr = parsePick(req, text)
+        if perr == nil { return response, nil }
+
+        var problem = &Request{
+            Kind:    KindAlert,
+            Message: perr.Error(),
+            Options: Options{Title: req.Title, Parent: req.Parent},
+        }
+        if _, err := messageBox(problem, windows.MB_OK | windows.MB_ICONWARNING, nil); err != nil {
+            return Response{}, err
+        }
+        prompt.Default = text
+    }
+}
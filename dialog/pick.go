@@ -0,0 +1,230 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "image/color"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// dateFormat is the format of a date typed as text, such as "2006-01-02".
+const dateFormat = "2006-01-02"
+
+// PickDate displays a modal dialog with a title, a message, and a calendar
+// initially showing today's date. It returns the date selected by the user,
+// at midnight in the local time zone, and true, or false if the user
+// cancelled the dialog.
+//
+// An error is returned if the dialog could not be displayed at all.
+func PickDate(title string, message string) (time.Time, bool, error) {
+    return Options{Title: title}.PickDate(message, time.Time{})
+}
+
+// PickColor displays a modal dialog with a title for selecting a colour,
+// initially white. It returns the opaque colour selected by the user and
+// true, or false if the user cancelled the dialog.
+//
+// An error is returned if the dialog could not be displayed at all.
+func PickColor(title string) (color.RGBA, bool, error) {
+    return Options{Title: title}.PickColor(color.RGBA{255, 255, 255, 255})
+}
+
+// PickNumber displays a modal dialog with a title, a message, and a slider
+// for selecting a whole number from min to max inclusive, in increments of
+// step from min, initially min. It returns the number selected by the user
+// and true, or false if the user cancelled the dialog.
+//
+// If max is less than min, they are swapped. A step less than 1 is treated
+// as 1.
+//
+// An error is returned if the dialog could not be displayed at all.
+func PickNumber(title string, message string, min, max, step int) (int, bool, error) {
+    return Options{Title: title}.PickNumber(message, min, max, step, min)
+}
+
+// PickDate displays a modal dialog with a message and a calendar initially
+// showing the date initial, or today's date if initial is the zero time. See
+// the PickDate function.
+func (o Options) PickDate(message string, initial time.Time) (time.Time, bool, error) {
+    return o.PickDateContext(context.Background(), message, initial)
+}
+
+// PickDateContext is like PickDate, but closes the dialog early if ctx is
+// done.
+func (o Options) PickDateContext(ctx context.Context, message string, initial time.Time) (time.Time, bool, error) {
+    if initial.IsZero() { initial = time.Now() }
+
+    var req = o.request(KindPickDate, message)
+    req.Date = midnight(initial)
+
+    var response, _, err = show(ctx, req)
+    if errors.Is(err, ErrCancelled) { return time.Time{}, false, nil }
+    if err != nil { return time.Time{}, false, err }
+    return midnight(response.Date), true, nil
+}
+
+// PickColor displays a modal dialog for selecting a colour, initially the
+// colour initial. See the PickColor function.
+func (o Options) PickColor(initial color.RGBA) (color.RGBA, bool, error) {
+    return o.PickColorContext(context.Background(), initial)
+}
+
+// PickColorContext is like PickColor, but closes the dialog early if ctx is
+// done.
+func (o Options) PickColorContext(ctx context.Context, initial color.RGBA) (color.RGBA, bool, error) {
+    var req = o.request(KindPickColor, "")
+    req.Color = initial
+
+    var response, _, err = show(ctx, req)
+    if errors.Is(err, ErrCancelled) { return color.RGBA{}, false, nil }
+    if err != nil { return color.RGBA{}, false, err }
+    return response.Color, true, nil
+}
+
+// PickNumber displays a modal dialog with a message and a slider for
+// selecting a whole number, initially initial, rounded down to the nearest
+// step. See the PickNumber function.
+func (o Options) PickNumber(message string, min, max, step, initial int) (int, bool, error) {
+    return o.PickNumberContext(context.Background(), message, min, max, step, initial)
+}
+
+// PickNumberContext is like PickNumber, but closes the dialog early if ctx is
+// done.
+func (o Options) PickNumberContext(ctx context.Context, message string, min, max, step, initial int) (int, bool, error) {
+    if max < min { min, max = max, min }
+    if step < 1 { step = 1 }
+
+    var req = o.request(KindPickNumber, message)
+    req.Min, req.Max, req.Step = min, max, step
+    req.Number = snapNumber(initial, min, max, step)
+
+    var response, _, err = show(ctx, req)
+    if errors.Is(err, ErrCancelled) { return 0, false, nil }
+    if err != nil { return 0, false, err }
+    return response.Number, true, nil
+}
+
+// midnight returns the start of the day of t, in the local time zone.
+func midnight(t time.Time) time.Time {
+    var year, month, day = t.Date()
+    return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// snapNumber returns the number nearest to n, rounded down, that is between
+// min and max inclusive and a whole number of steps from min.
+func snapNumber(n, min, max, step int) int {
+    if n < min { return min }
+    if n > max { n = max }
+    return n - (n - min) % step
+}
+
+// parseColor parses a colour in one of the forms "#rgb", "#rrggbb",
+// "#rrrrggggbbbb", "rgb(r, g, b)" or "rgba(r, g, b, a)", where r, g and b
+// are from 0 to 255. Any transparency is ignored.
+func parseColor(s string) (color.RGBA, bool) {
+    s = strings.ToLower(strings.TrimSpace(s))
+
+    if strings.HasPrefix(s, "#") {
+        var hex = s[1:]
+        var digits = len(hex) / 3
+        if (digits < 1) || (digits > 4) || (len(hex) != 3 * digits) { return color.RGBA{}, false }
+
+        var c = color.RGBA{A: 255}
+        for i, channel := range []*uint8{&c.R, &c.G, &c.B} {
+            var v, err = strconv.ParseUint(hex[i*digits:(i+1)*digits], 16, 16)
+            if err != nil { return color.RGBA{}, false }
+
+            // scale to 8 bits e.g. "f" to 0xff, or "ffff" to 0xff
+            switch digits {
+                case 1: v *= 0x11
+                case 3: v >>= 4
+                case 4: v >>= 8
+            }
+            *channel = uint8(v)
+        }
+        return c, true
+    }
+
+    var args string
+    switch {
+        case strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")"):
+            args = s[4:len(s)-1]
+        case strings.HasPrefix(s, "rgba(") && strings.HasSuffix(s, ")"):
+            args = s[5:len(s)-1]
+            if index := strings.LastIndex(args, ","); index >= 0 { args = args[0:index] }
+        default:
+            return color.RGBA{}, false
+    }
+
+    var fields = strings.Split(args, ",")
+    if len(fields) != 3 { return color.RGBA{}, false }
+
+    var c = color.RGBA{A: 255}
+    for i, channel := range []*uint8{&c.R, &c.G, &c.B} {
+        var v, err = strconv.ParseUint(strings.TrimSpace(fields[i]), 10, 8)
+        if err != nil { return color.RGBA{}, false }
+        *channel = uint8(v)
+    }
+    return c, true
+}
+
+// formatColor formats a colour in the form "#rrggbb".
+func formatColor(c color.RGBA) string {
+    return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// pickText returns the initial value of a KindPickDate, KindPickColor or
+// KindPickNumber request as text, and a hint describing what can be typed.
+func pickText(req *Request) (string, string) {
+    switch req.Kind {
+        case KindPickDate:
+            return req.Date.Format(dateFormat), "YYYY-MM-DD"
+
+        case KindPickColor:
+            return formatColor(req.Color), "#RRGGBB"
+
+        case KindPickNumber:
+            var hint = fmt.Sprintf("%d to %d", req.Min, req.Max)
+            if req.Step > 1 { hint += fmt.Sprintf(" in steps of %d", req.Step) }
+            return strconv.Itoa(req.Number), hint
+
+        default:
+            return "", ""
+    }
+}
+
+// parsePick parses text typed in response to a KindPickDate, KindPickColor
+// or KindPickNumber request. An empty string is taken to mean the initial
+// value. If the text isn't valid, the error explains why.
+func parsePick(req *Request, text string) (Response, error) {
+    text = strings.TrimSpace(text)
+    if len(text) == 0 { text, _ = pickText(req) }
+
+    switch req.Kind {
+        case KindPickDate:
+            var date, err = time.ParseInLocation(dateFormat, text, time.Local)
+            if err != nil { return Response{}, fmt.Errorf("%q is not a date in the form YYYY-MM-DD", text) }
+            return Response{Date: date}, nil
+
+        case KindPickColor:
+            var c, ok = parseColor(text)
+            if !ok { return Response{}, fmt.Errorf("%q is not a colour in the form #RRGGBB", text) }
+            return Response{Color: c}, nil
+
+        case KindPickNumber:
+            var n, err = strconv.Atoi(text)
+            if (err != nil) || (n < req.Min) || (n > req.Max) {
+                return Response{}, fmt.Errorf("%q is not a whole number from %d to %d", text, req.Min, req.Max)
+            }
+            if (n - req.Min) % req.Step != 0 {
+                return Response{}, fmt.Errorf("%d is not %d plus a multiple of %d", n, req.Min, req.Step)
+            }
+            return Response{Number: n}, nil
+
+        default:
+            return Response{}, ErrUnsupported
+    }
+}
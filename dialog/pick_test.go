@@ -0,0 +1,97 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "image/color"
+    "testing"
+    "time"
+)
+
+func TestParseColor(t *testing.T) {
+    var tests = []struct{
+        input string
+        expected color.RGBA
+        ok bool
+    }{
+        {"#ff8000",             color.RGBA{255, 128, 0, 255},   true},
+        {" #FF8000 ",           color.RGBA{255, 128, 0, 255},   true},
+        {"#f80",                color.RGBA{255, 136, 0, 255},   true},
+        {"#ffff80800000",       color.RGBA{255, 128, 0, 255},   true},
+        {"rgb(255,128,0)",      color.RGBA{255, 128, 0, 255},   true},
+        {"rgb(1, 2, 3)",        color.RGBA{1, 2, 3, 255},       true},
+        {"rgba(1,2,3,0.5)",     color.RGBA{1, 2, 3, 255},       true},
+        {"",                    color.RGBA{},                   false},
+        {"ff8000",              color.RGBA{},                   false},
+        {"#ff800",              color.RGBA{},                   false},
+        {"#gg8000",             color.RGBA{},                   false},
+        {"rgb(256,0,0)",        color.RGBA{},                   false},
+        {"rgb(1,2)",            color.RGBA{},                   false},
+        {"red",                 color.RGBA{},                   false},
+    }
+
+    for index, test := range tests {
+        var result, ok = parseColor(test.input)
+        if (result != test.expected) || (ok != test.ok) {
+            t.Errorf("Test %d: got (%v, %t) but wanted (%v, %t)",
+                index, result, ok, test.expected, test.ok)
+        }
+    }
+}
+
+func TestSnapNumber(t *testing.T) {
+    var tests = []struct{
+        n, min, max, step int
+        expected int
+    }{
+        {5,   0, 10, 1, 5},
+        {-5,  0, 10, 1, 0},
+        {15,  0, 10, 1, 10},
+        {7,   0, 10, 5, 5},
+        {12,  0, 12, 5, 10},
+        {4,   1, 10, 3, 4},
+        {6,   1, 10, 3, 4},
+        {-3, -10, 10, 4, -6},
+    }
+
+    for index, test := range tests {
+        if result := snapNumber(test.n, test.min, test.max, test.step); result != test.expected {
+            t.Errorf("Test %d: got %d but wanted %d", index, result, test.expected)
+        }
+    }
+}
+
+func TestParsePick(t *testing.T) {
+    var date = &Request{Kind: KindPickDate, Date: time.Date(2020, 2, 29, 0, 0, 0, 0, time.Local)}
+    var colour = &Request{Kind: KindPickColor, Color: color.RGBA{1, 2, 3, 255}}
+    var number = &Request{Kind: KindPickNumber, Min: 1, Max: 10, Step: 3, Number: 4}
+
+    var tests = []struct{
+        req *Request
+        input string
+        expected Response
+        isErr bool
+    }{
+        {date,   "2021-12-25\n", Response{Date: time.Date(2021, 12, 25, 0, 0, 0, 0, time.Local)}, false},
+        {date,   "",             Response{Date: date.Date}, false},
+        {date,   "2021-02-29",   Response{}, true},
+        {date,   "25/12/2021",   Response{}, true},
+        {colour, "#ffffff",      Response{Color: color.RGBA{255, 255, 255, 255}}, false},
+        {colour, " ",            Response{Color: colour.Color}, false},
+        {colour, "white",        Response{}, true},
+        {number, "7",            Response{Number: 7}, false},
+        {number, "",             Response{Number: 4}, false},
+        {number, "5",            Response{}, true},
+        {number, "11",           Response{}, true},
+        {number, "four",         Response{}, true},
+    }
+
+    for index, test := range tests {
+        var result, err = parsePick(test.req, test.input)
+        if (err != nil) != test.isErr {
+            t.Errorf("Test %d: unexpected error status: %v", index, err)
+        } else if !result.Date.Equal(test.expected.Date) ||
+            (result.Color != test.expected.Color) ||
+            (result.Number != test.expected.Number) {
+            t.Errorf("Test %d: got %+v but wanted %+v", index, result, test.expected)
+        }
+    }
+}
@@ -0,0 +1,199 @@
+// +build linux
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "fmt"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync/atomic"
+    "time"
+)
+
+// portalProvider uses the xdg-desktop-portal D-Bus service, which is
+// available on Wayland and inside sandboxes such as Flatpak even when no
+// dialog programs are installed. It only supports file dialogs and
+// notifications.
+type portalProvider struct {
+    address string // D-Bus session bus address
+}
+
+const (
+    portalService = "org.freedesktop.portal.Desktop"
+    portalPath    = "/org/freedesktop/portal/desktop"
+)
+
+// response codes of the org.freedesktop.portal.Request.Response signal
+const (
+    portalSuccess   = 0
+    portalCancelled = 1
+)
+
+// portalTokens makes each request handle unique within this process.
+var portalTokens uint32
+
+func (p portalProvider) Name() string { return "portal" }
+
+func (p portalProvider) Show(ctx context.Context, req *Request) (Response, error) {
+    switch req.Kind {
+        case KindOpenFile, KindOpenFiles, KindSaveFile, KindSelectFolder:
+            return p.file(ctx, req)
+
+        case KindNotify:
+            return Response{}, p.notify(ctx, req)
+
+        default:
+            return Response{}, ErrUnsupported
+    }
+}
+
+// portalRequestPath returns the object path of the Request object that the
+// portal will create for a given handle_token, so that we can listen for its
+// response before making the request.
+func portalRequestPath(sender string, token string) string {
+    var name = strings.ReplaceAll(strings.TrimPrefix(sender, ":"), ".", "_")
+    return portalPath + "/request/" + name + "/" + token
+}
+
+// portalParent returns the parent window identifier for a request.
+func portalParent(req *Request) string {
+    if req.Parent == 0 { return "" }
+    return fmt.Sprintf("x11:%x", req.Parent)
+}
+
+// portalFileOptions returns the a{sv} options for a FileChooser request.
+func portalFileOptions(req *Request, token string) []interface{} {
+    var options = []interface{}{
+        dbusEntry("handle_token", "s", token),
+        dbusEntry("modal", "b", true),
+    }
+
+    switch req.Kind {
+        case KindOpenFiles:    options = append(options, dbusEntry("multiple", "b", true))
+        case KindSelectFolder: options = append(options, dbusEntry("directory", "b", true))
+        case KindSaveFile:
+            if len(req.Default) > 0 { options = append(options, dbusEntry("current_name", "s", req.Default)) }
+    }
+
+    if len(req.Directory) > 0 {
+        // a null-terminated byte string, as file paths needn't be UTF-8
+        options = append(options, dbusEntry("current_folder", "ay", []byte(req.Directory + "\x00")))
+    }
+
+    if len(req.Filters) > 0 {
+        // a(sa(us)): a name and a list of (0 for a glob pattern, pattern)
+        var filters = make([]interface{}, 0, len(req.Filters))
+        for _, f := range req.Filters {
+            var patterns = make([]interface{}, 0, len(f.Patterns))
+            for _, pattern := range f.Patterns {
+                patterns = append(patterns, []interface{}{uint32(0), pattern})
+            }
+            filters = append(filters, []interface{}{f.Name, patterns})
+        }
+        options = append(options, dbusEntry("filters", "a(sa(us))", filters))
+    }
+
+    return options
+}
+
+// portalPaths converts the URIs returned by a FileChooser into file paths.
+func portalPaths(results interface{}) ([]string, error) {
+    var value, _ = dbusLookup(results, "uris")
+    var uris, _ = value.([]string)
+
+    var paths = make([]string, 0, len(uris))
+    for _, uri := range uris {
+        var u, err = url.Parse(uri)
+        if err != nil { return nil, err }
+        if u.Scheme != "file" { return nil, fmt.Errorf("portal: unsupported URI %q", uri) }
+        paths = append(paths, u.Path)
+    }
+    return paths, nil
+}
+
+func (p portalProvider) file(ctx context.Context, req *Request) (Response, error) {
+    var conn, err = dialDBus(p.address)
+    if err != nil { return Response{}, err }
+    defer conn.Close()
+
+    // listen for the response before making the request, so it can't be missed
+    var responses = make(chan *dbusMessage, 8)
+    conn.handle(func(m *dbusMessage) {
+        if (m.kind != dbusSignal) || (m.member != "Response") { return }
+        select {
+            case responses <- m:
+            default:
+        }
+    })
+
+    var token = fmt.Sprintf("tawesoft%d", atomic.AddUint32(&portalTokens, 1))
+    var handle = portalRequestPath(conn.name, token)
+    _, err = conn.call(ctx, "org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus",
+        "AddMatch", "s", "type='signal',interface='org.freedesktop.portal.Request',member='Response'")
+    if err != nil { return Response{}, err }
+
+    var method = "OpenFile"
+    if req.Kind == KindSaveFile { method = "SaveFile" }
+
+    reply, err := conn.call(ctx, portalService, portalPath, "org.freedesktop.portal.FileChooser",
+        method, "ssa{sv}", portalParent(req), req.Title, portalFileOptions(req, token))
+    if err != nil { return Response{}, err }
+
+    // older portals may return a different handle
+    if len(reply) == 0 { return Response{}, fmt.Errorf("portal: empty reply to %s", method) }
+    if s, ok := reply[0].(string); ok { handle = s }
+
+    for {
+        select {
+            case m := <-responses:
+                if (m.path != handle) || (len(m.body) != 2) { continue }
+
+                switch code, _ := m.body[0].(uint32); code {
+                    case portalSuccess:
+                        var paths, err = portalPaths(m.body[1])
+                        if (err == nil) && (len(paths) == 0) { err = ErrCancelled }
+                        return Response{Paths: paths}, err
+                    case portalCancelled:
+                        return Response{}, ErrCancelled
+                    default:
+                        return Response{}, fmt.Errorf("portal: %s request failed", method)
+                }
+
+            case <-conn.closed:
+                return Response{}, conn.err
+
+            case <-ctx.Done():
+                // close the dialog, but don't wait long if the portal is stuck
+                var closeCtx, cancel = context.WithTimeout(context.Background(), time.Second)
+                conn.call(closeCtx, portalService, handle, "org.freedesktop.portal.Request", "Close", "")
+                cancel()
+                return Response{}, ctx.Err()
+        }
+    }
+}
+
+func (p portalProvider) notify(ctx context.Context, req *Request) error {
+    var conn, err = dialDBus(p.address)
+    if err != nil { return err }
+    defer conn.Close()
+
+    var priority = "normal"
+    switch req.Level {
+        case LevelWarning: priority = "high"
+        case LevelError:   priority = "urgent"
+    }
+
+    var id = fmt.Sprintf("%s-%d", filepath.Base(os.Args[0]), atomic.AddUint32(&portalTokens, 1))
+    var notification = []interface{}{
+        dbusEntry("title", "s", req.Title),
+        dbusEntry("body", "s", req.Message),
+        dbusEntry("priority", "s", priority),
+    }
+
+    _, err = conn.call(ctx, portalService, portalPath, "org.freedesktop.portal.Notification",
+        "AddNotification", "sa{sv}", id, notification)
+    return err
+}
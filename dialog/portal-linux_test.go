@@ -0,0 +1,262 @@
+// +build linux
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "io/ioutil"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+// testBus starts a private D-Bus session bus, or skips the test if
+// dbus-daemon isn't installed, and returns its address.
+func testBus(t *testing.T) string {
+    var daemon, err = exec.LookPath("dbus-daemon")
+    if err != nil { t.Skip("dbus-daemon not installed") }
+
+    var dir = t.TempDir()
+    var config = filepath.Join(dir, "bus.conf")
+    err = ioutil.WriteFile(config, []byte(`<!DOCTYPE busconfig PUBLIC
+ "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:path=`+filepath.Join(dir, "bus")+`</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`), 0600)
+    if err != nil { t.Fatal(err) }
+
+    var cmd = exec.Command(daemon, "--config-file="+config, "--nofork", "--print-address")
+    stdout, err := cmd.StdoutPipe()
+    if err != nil { t.Fatal(err) }
+    if err := cmd.Start(); err != nil { t.Fatal(err) }
+    t.Cleanup(func() {
+        cmd.Process.Kill()
+        cmd.Wait()
+    })
+
+    address, err := bufio.NewReader(stdout).ReadString('\n')
+    if err != nil { t.Fatalf("dbus-daemon: %v", err) }
+    return strings.TrimSpace(address)
+}
+
+// fakePortal implements just enough of xdg-desktop-portal for testing.
+type fakePortal struct {
+    conn *dbusConn
+
+    mutex sync.Mutex
+
+    // code and uris are the response to each FileChooser request
+    code uint32
+    uris []string
+
+    calls []*dbusMessage
+    closed []string
+}
+
+func newFakePortal(t *testing.T, address string) *fakePortal {
+    var conn, err = dialDBus(address)
+    if err != nil { t.Fatal(err) }
+    t.Cleanup(func() { conn.Close() })
+
+    var p = &fakePortal{conn: conn}
+    conn.handle(p.handle)
+
+    _, err = conn.call(context.Background(), "org.freedesktop.DBus", "/org/freedesktop/DBus",
+        "org.freedesktop.DBus", "RequestName", "su", portalService, uint32(4))
+    if err != nil { t.Fatal(err) }
+
+    return p
+}
+
+func (p *fakePortal) handle(m *dbusMessage) {
+    if m.kind != dbusMethodCall { return }
+
+    p.mutex.Lock()
+    p.calls = append(p.calls, m)
+    var code, uris = p.code, p.uris
+    p.mutex.Unlock()
+
+    switch m.iface + "." + m.member {
+        case "org.freedesktop.portal.FileChooser.OpenFile",
+             "org.freedesktop.portal.FileChooser.SaveFile":
+            var token, _ = dbusLookup(m.body[2], "handle_token")
+            var handle = portalRequestPath(m.sender, token.(string))
+            if code == 254 { p.conn.reply(m, ""); return } // reply without a handle
+
+            p.conn.reply(m, "o", handle)
+
+            if code == 255 { return } // never respond
+
+            p.conn.emit(handle, "org.freedesktop.portal.Request", "Response", "ua{sv}",
+                code, []interface{}{dbusEntry("uris", "as", uris)})
+
+        case "org.freedesktop.portal.Request.Close":
+            p.mutex.Lock()
+            p.closed = append(p.closed, m.path)
+            p.mutex.Unlock()
+            p.conn.reply(m, "")
+
+        default:
+            p.conn.reply(m, "")
+    }
+}
+
+func (p *fakePortal) lastCall() *dbusMessage {
+    p.mutex.Lock()
+    defer p.mutex.Unlock()
+
+    if len(p.calls) == 0 { return nil }
+    return p.calls[len(p.calls)-1]
+}
+
+func TestPortalFile(t *testing.T) {
+    var address = testBus(t)
+    var portal = newFakePortal(t, address)
+    var provider = portalProvider{address: address}
+
+    var tests = []struct{
+        kind Kind
+        code uint32
+        uris []string
+        method string
+        options map[string]interface{}
+        expected []string
+        err error
+    }{
+        {KindOpenFile, portalSuccess, []string{"file:///tmp/a%20b.txt"}, "OpenFile",
+            map[string]interface{}{"current_folder": []byte("/tmp\x00")},
+            []string{"/tmp/a b.txt"}, nil},
+        {KindOpenFiles, portalSuccess, []string{"file:///a", "file:///b"}, "OpenFile",
+            map[string]interface{}{"multiple": true},
+            []string{"/a", "/b"}, nil},
+        {KindSaveFile, portalSuccess, []string{"file:///tmp/new.png"}, "SaveFile",
+            map[string]interface{}{"current_name": "new.png"},
+            []string{"/tmp/new.png"}, nil},
+        {KindSelectFolder, portalSuccess, []string{"file:///home"}, "OpenFile",
+            map[string]interface{}{"directory": true},
+            []string{"/home"}, nil},
+        {KindOpenFile, portalCancelled, nil, "OpenFile", nil, nil, ErrCancelled},
+    }
+
+    for index, test := range tests {
+        portal.mutex.Lock()
+        portal.code, portal.uris = test.code, test.uris
+        portal.mutex.Unlock()
+
+        var req = &Request{
+            Kind:      test.kind,
+            Options:   Options{Title: "Title"},
+            Directory: "/tmp",
+            Filters:   []Filter{{Name: "Images", Patterns: []string{"*.png"}}},
+        }
+        if test.kind == KindSaveFile { req.Default = "new.png" }
+
+        var response, err = provider.Show(context.Background(), req)
+        if !errors.Is(err, test.err) {
+            t.Errorf("Test %d: got error %v but wanted %v", index, err, test.err)
+            continue
+        } else if !reflect.DeepEqual(response.Paths, test.expected) {
+            t.Errorf("Test %d: got %v but wanted %v", index, response.Paths, test.expected)
+        }
+
+        var call = portal.lastCall()
+        if (call == nil) || (call.member != test.method) || (call.body[1] != "Title") {
+            t.Errorf("Test %d: unexpected call %+v", index, call)
+            continue
+        }
+        for key, expected := range test.options {
+            if value, _ := dbusLookup(call.body[2], key); !reflect.DeepEqual(value, expected) {
+                t.Errorf("Test %d: got option %s=%v but wanted %v", index, key, value, expected)
+            }
+        }
+        if filters, _ := dbusLookup(call.body[2], "filters"); filters == nil {
+            t.Errorf("Test %d: missing filters", index)
+        }
+    }
+}
+
+func TestPortalCancel(t *testing.T) {
+    var address = testBus(t)
+    var portal = newFakePortal(t, address)
+    portal.code = 255 // never respond
+
+    var ctx, cancel = context.WithTimeout(context.Background(), 100 * time.Millisecond)
+    defer cancel()
+
+    var _, err = portalProvider{address: address}.Show(ctx, &Request{Kind: KindOpenFile})
+    if !errors.Is(err, context.DeadlineExceeded) {
+        t.Errorf("got error %v but wanted %v", err, context.DeadlineExceeded)
+    }
+
+    portal.mutex.Lock()
+    defer portal.mutex.Unlock()
+    if len(portal.closed) != 1 {
+        t.Errorf("got %d calls to Close but wanted 1", len(portal.closed))
+    }
+}
+
+func TestPortalEmptyReply(t *testing.T) {
+    var address = testBus(t)
+    var portal = newFakePortal(t, address)
+    portal.code = 254 // reply without a handle
+
+    var _, err = portalProvider{address: address}.Show(context.Background(), &Request{Kind: KindOpenFile})
+    if err == nil { t.Errorf("expected an error") }
+}
+
+func TestPortalNotify(t *testing.T) {
+    var address = testBus(t)
+    var portal = newFakePortal(t, address)
+
+    var req = &Request{
+        Kind:    KindNotify,
+        Message: "Body",
+        Options: Options{Title: "Title", Level: LevelError},
+    }
+    if _, err := (portalProvider{address: address}).Show(context.Background(), req); err != nil {
+        t.Fatal(err)
+    }
+
+    var call = portal.lastCall()
+    if (call == nil) || (call.member != "AddNotification") {
+        t.Fatalf("unexpected call %+v", call)
+    }
+    for key, expected := range map[string]string{"title": "Title", "body": "Body", "priority": "urgent"} {
+        if value, _ := dbusLookup(call.body[1], key); value != expected {
+            t.Errorf("got %s=%v but wanted %q", key, value, expected)
+        }
+    }
+}
+
+func TestPortalUnavailable(t *testing.T) {
+    var address = testBus(t)
+
+    // no portal service on this bus
+    var _, err = portalProvider{address: address}.Show(context.Background(), &Request{Kind: KindOpenFile})
+    if err == nil { t.Errorf("expected an error") }
+
+    _, err = portalProvider{address: address}.Show(context.Background(), &Request{Kind: KindAlert})
+    if !errors.Is(err, ErrUnsupported) {
+        t.Errorf("got error %v but wanted %v", err, ErrUnsupported)
+    }
+
+    _, err = portalProvider{address: "unix:path=" + filepath.Join(os.TempDir(), "no-such-bus")}.
+        Show(context.Background(), &Request{Kind: KindOpenFile})
+    if err == nil { t.Errorf("expected an error") }
+}
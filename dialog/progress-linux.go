@@ -0,0 +1,154 @@
+// +build linux
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "os/exec"
+    "strings"
+)
+
+func (p zenityProvider) ShowProgress(ctx context.Context, req *Request, cancel func()) (ProgressView, error) {
+    var args = func(text string, pulsate bool) []string {
+        var args = append(p.args(req, "--progress"), "--percentage=0", "--text="+zenityMarkup(text))
+        if pulsate { args = append(args, "--pulsate") }
+        return args
+    }
+    return startPipeProgress(ctx, p.command, args, req.Message, cancel)
+}
+
+func (p yadProvider) ShowProgress(ctx context.Context, req *Request, cancel func()) (ProgressView, error) {
+    var args = func(text string, pulsate bool) []string {
+        var args = []string{"--progress", "--title", req.Title, "--center", "--percentage=0",
+            "--text="+escapeMarkup(text)}
+        if req.Width > 0 {
+            args = append(args, fmt.Sprintf("--width=%d", req.Width))
+        } else {
+            args = append(args, "--width=400")
+        }
+        if pulsate { args = append(args, "--pulsate") }
+        return args
+    }
+    return startPipeProgress(ctx, p.command, args, req.Message, cancel)
+}
+
+// pipeProgress is a progress dialog displayed by zenity or yad, which is
+// updated by writing lines to its stdin: a number for the percentage
+// complete, or "#" followed by text to display.
+//
+// Either program can only pulsate for the whole life of a dialog, so the
+// first Pulse restarts the dialog with --pulsate, and the next SetPercent
+// restarts it again without. A restarted dialog is a new window, which the
+// window manager may put somewhere else, so this is documented on
+// ProgressDialog.Pulse.
+type pipeProgress struct {
+    ctx context.Context
+    command string
+    args func(text string, pulsate bool) []string
+    cancel func()
+
+    text string
+    pulsing bool
+
+    // the current process, if running
+    running bool
+    stdin io.WriteCloser
+    stop context.CancelFunc
+    closed chan struct{}
+    done chan struct{}
+}
+
+// startPipeProgress starts a command that displays a progress dialog, with
+// the arguments returned by args. If the command exits before the view is
+// closed, the user cancelled the dialog.
+func startPipeProgress(
+    ctx context.Context,
+    command string,
+    args func(text string, pulsate bool) []string,
+    text string,
+    cancel func(),
+) (ProgressView, error) {
+    var p = &pipeProgress{
+        ctx: ctx,
+        command: command,
+        args: args,
+        cancel: cancel,
+        text: text,
+    }
+
+    if err := p.start(false); err != nil { return nil, err }
+    return p, nil
+}
+
+// start starts a new process to display the dialog.
+func (p *pipeProgress) start(pulsate bool) error {
+    var ctx, stop = context.WithCancel(p.ctx)
+
+    var cmd = exec.CommandContext(ctx, p.command, p.args(p.text, pulsate)...)
+    var stdin, err = cmd.StdinPipe()
+    if err != nil { stop(); return err }
+
+    if err := cmd.Start(); err != nil {
+        stop()
+        return err
+    }
+
+    var closed, done = make(chan struct{}), make(chan struct{})
+    p.stdin, p.stop, p.closed, p.done = stdin, stop, closed, done
+    p.pulsing, p.running = pulsate, true
+
+    go func() {
+        cmd.Wait()
+        close(done)
+
+        select {
+            case <-closed:
+            default: p.cancel()
+        }
+    }()
+
+    return nil
+}
+
+// end stops the current process, without cancelling the dialog.
+func (p *pipeProgress) end() {
+    if !p.running { return }
+    p.running = false
+
+    close(p.closed)
+    p.stdin.Close()
+    p.stop() // kills the process, otherwise it waits for the user to press OK
+    <-p.done
+}
+
+func (p *pipeProgress) SetPercent(percent int) error {
+    if p.pulsing {
+        p.end()
+        if err := p.start(false); err != nil { return err }
+    }
+
+    var _, err = fmt.Fprintf(p.stdin, "%d\n", percent)
+    return err
+}
+
+func (p *pipeProgress) SetText(text string) error {
+    p.text = text
+
+    // each line must be a single line
+    var _, err = fmt.Fprintf(p.stdin, "# %s\n", strings.ReplaceAll(text, "\n", " "))
+    return err
+}
+
+func (p *pipeProgress) Pulse() error {
+    if p.pulsing { return nil }
+
+    p.end()
+    return p.start(true)
+}
+
+func (p *pipeProgress) Close() error {
+    p.end()
+    return nil
+}
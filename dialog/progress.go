@@ -0,0 +1,154 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "sync"
+)
+
+// ProgressView is a progress dialog displayed by a ProgressProvider, which
+// stays open while it is updated.
+//
+// SetPercent and Pulse switch between a progress bar that shows a percentage
+// complete, and one that only shows that something is happening. A view that
+// can't show one of these should ignore it. An error from any method means
+// that the view can no longer be updated.
+type ProgressView interface {
+    SetPercent(percent int) error
+    SetText(text string) error
+    Pulse() error
+    Close() error
+}
+
+// ProgressProvider is a Provider that can also display a progress dialog, for
+// a Request of KindProgress.
+//
+// ShowProgress displays the dialog and returns as soon as it is visible. If
+// the user cancels the dialog, the provider calls cancel. The provider should
+// stop displaying the dialog when ctx is done.
+type ProgressProvider interface {
+    Provider
+    ShowProgress(ctx context.Context, req *Request, cancel func()) (ProgressView, error)
+}
+
+// ProgressDialog is a handle to a progress dialog returned by Progress. Its
+// methods are safe to call from multiple goroutines.
+type ProgressDialog struct {
+    mutex sync.Mutex
+    view ProgressView
+    closed bool
+    stop context.CancelFunc
+
+    cancelOnce sync.Once
+    cancelled chan struct{}
+}
+
+// Progress displays a progress dialog with a title, and returns a handle
+// that updates it until it is closed. Unlike the other dialogs, Progress
+// returns as soon as the dialog is visible.
+//
+// On Linux, this uses zenity or yad, otherwise a progress bar is redrawn on
+// stderr.
+//
+// If no provider was able to display the dialog, the returned error is a
+// *ProviderError that wraps ErrNoProvider.
+func Progress(title string) (*ProgressDialog, error) {
+    return Options{Title: title}.Progress("")
+}
+
+// Progress displays a progress dialog with a message, which can be changed
+// later with SetText. See the Progress function.
+func (o Options) Progress(message string) (*ProgressDialog, error) {
+    return o.ProgressContext(context.Background(), message)
+}
+
+// ProgressContext is like Progress, but closes the dialog when ctx is done,
+// which also closes the channel returned by Cancelled.
+func (o Options) ProgressContext(ctx context.Context, message string) (*ProgressDialog, error) {
+    var req = o.request(KindProgress, message)
+    ctx, stop := context.WithCancel(ctx)
+    var dialog = &ProgressDialog{
+        stop: stop,
+        cancelled: make(chan struct{}),
+    }
+
+    var providers, failures = selectProviders()
+
+    for _, p := range providers {
+        var pp, ok = p.(ProgressProvider)
+        if !ok {
+            failures = append(failures, Failure{Provider: p.Name(), Err: ErrUnsupported})
+            continue
+        }
+
+        var view, err = pp.ShowProgress(ctx, req, dialog.cancel)
+        if err != nil {
+            failures = append(failures, Failure{Provider: p.Name(), Err: err})
+            continue
+        }
+
+        dialog.view = view
+        return dialog, nil
+    }
+
+    stop()
+    return nil, &ProviderError{Failures: failures}
+}
+
+// cancel marks the dialog as cancelled by the user, or as no longer able to
+// be updated.
+func (d *ProgressDialog) cancel() {
+    d.cancelOnce.Do(func() { close(d.cancelled) })
+}
+
+// update calls f with the view, unless the dialog is closed. If f fails, the
+// dialog is treated as cancelled.
+func (d *ProgressDialog) update(f func(view ProgressView) error) {
+    d.mutex.Lock()
+    defer d.mutex.Unlock()
+
+    if d.closed { return }
+    if err := f(d.view); err != nil { d.cancel() }
+}
+
+// SetPercent sets the percentage complete, from 0 to 100.
+func (d *ProgressDialog) SetPercent(percent int) {
+    if percent < 0   { percent = 0 }
+    if percent > 100 { percent = 100 }
+    d.update(func(view ProgressView) error { return view.SetPercent(percent) })
+}
+
+// SetText replaces the message displayed alongside the progress bar.
+func (d *ProgressDialog) SetText(text string) {
+    d.update(func(view ProgressView) error { return view.SetText(text) })
+}
+
+// Pulse shows that something is happening when the percentage complete isn't
+// known, until the next call to SetPercent. Call it periodically.
+//
+// zenity and yad can't switch a dialog between pulsing and showing a
+// percentage, so with either of them, the first Pulse after SetPercent (or
+// the first SetPercent after Pulse) closes the dialog and opens a new one in
+// its place. The dialog flickers, and may move, each time, so avoid switching
+// back and forth often.
+func (d *ProgressDialog) Pulse() {
+    d.update(func(view ProgressView) error { return view.Pulse() })
+}
+
+// Cancelled returns a channel that is closed when the user cancels the
+// dialog, or if it could no longer be updated.
+func (d *ProgressDialog) Cancelled() <-chan struct{} {
+    return d.cancelled
+}
+
+// Close closes the dialog. Any further updates are ignored.
+func (d *ProgressDialog) Close() error {
+    d.mutex.Lock()
+    defer d.mutex.Unlock()
+
+    if d.closed { return nil }
+    d.closed = true
+
+    var err = d.view.Close()
+    d.stop()
+    return err
+}
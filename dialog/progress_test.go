@@ -0,0 +1,70 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "errors"
+    "reflect"
+    "testing"
+)
+
+type testProgressProvider struct {
+    testProvider
+    view *testProgressView
+}
+
+func (p testProgressProvider) ShowProgress(ctx context.Context, req *Request, cancel func()) (ProgressView, error) {
+    return p.view, nil
+}
+
+type testProgressView struct {
+    updates []interface{}
+    err error
+}
+
+func (v *testProgressView) SetPercent(percent int) error { v.updates = append(v.updates, percent); return v.err }
+func (v *testProgressView) SetText(text string) error    { v.updates = append(v.updates, text); return v.err }
+func (v *testProgressView) Pulse() error                 { v.updates = append(v.updates, "pulse"); return v.err }
+func (v *testProgressView) Close() error                 { v.updates = append(v.updates, "close"); return nil }
+
+func TestProgress(t *testing.T) {
+    useProviders(t)
+
+    var view = &testProgressView{}
+    SetProviders(
+        testProvider{name: "other", kinds: []Kind{KindAlert}},
+        testProgressProvider{testProvider{name: "progress"}, view},
+    )
+
+    var dialog, err = Progress("Title")
+    if err != nil { t.Fatalf("unexpected error: %v", err) }
+
+    dialog.SetPercent(150)
+    dialog.SetText("Working")
+    dialog.Pulse()
+    dialog.Close()
+    dialog.SetPercent(50) // ignored after close
+
+    var expected = []interface{}{100, "Working", "pulse", "close"}
+    if !reflect.DeepEqual(view.updates, expected) {
+        t.Errorf("got updates %v but wanted %v", view.updates, expected)
+    }
+
+    select {
+        case <-dialog.Cancelled(): t.Errorf("unexpected cancellation")
+        default:
+    }
+
+    // a view that can't be updated is treated as cancelled
+    view.err = errors.New("broken")
+    dialog, _ = Progress("Title")
+    dialog.SetPercent(10)
+    select {
+        case <-dialog.Cancelled():
+        default: t.Errorf("expected cancellation")
+    }
+
+    SetProviders(testProvider{name: "other", kinds: []Kind{KindAlert}})
+    if _, err = Progress("Title"); !errors.Is(err, ErrNoProvider) {
+        t.Errorf("expected ErrNoProvider, got %v", err)
+    }
+}
@@ -0,0 +1,184 @@
+// +build windows
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "fmt"
+    "sync"
+    "unsafe"
+
+    "golang.org/x/sys/windows"
+)
+
+// Windows has no stock text entry dialog, so one is built at runtime from an
+// in-memory DLGTEMPLATE and shown with DialogBoxIndirectParamW.
+
+var (
+    procDialogBoxIndirectParamW = user32.NewProc("DialogBoxIndirectParamW")
+    procEndDialog               = user32.NewProc("EndDialog")
+    procGetDlgItem              = user32.NewProc("GetDlgItem")
+    procGetDlgItemTextW         = user32.NewProc("GetDlgItemTextW")
+    procGetWindowTextLengthW    = user32.NewProc("GetWindowTextLengthW")
+    procSetDlgItemTextW         = user32.NewProc("SetDlgItemTextW")
+    procSetTimer                = user32.NewProc("SetTimer")
+    procKillTimer               = user32.NewProc("KillTimer")
+)
+
+const (
+    wmInitDialog = 0x0110
+    wmCommand    = 0x0111
+    wmTimer      = 0x0113
+
+    idOk       = 1
+    idEdit     = 100
+    idText     = 101
+    idTimer    = 102
+    idTimeout  = 103 // result of a dialog that timed out
+
+    dsSetFont       = 0x00000040
+    dsModalFrame    = 0x00000080
+    dsSetForeground = 0x00000200
+    dsCenter        = 0x00000800
+    wsPopup         = 0x80000000
+    wsChild         = 0x40000000
+    wsVisible       = 0x10000000
+    wsCaption       = 0x00C00000
+    wsBorder        = 0x00800000
+    wsSysMenu       = 0x00080000
+    wsTabStop       = 0x00010000
+    esPassword      = 0x00000020
+    esAutoHScroll   = 0x00000080
+    bsPushButton    = 0x00000000
+    bsDefPushButton = 0x00000001
+
+    classButton = 0x0080
+    classEdit   = 0x0081
+    classStatic = 0x0082
+)
+
+// promptState is shared with the dialog procedure. Only one prompt is shown
+// at a time, so a single callback and state is reused for every prompt.
+var promptState struct {
+    sync.Mutex
+    defaultValue []uint16
+    timeout uintptr // milliseconds, or zero
+    result string
+}
+
+var promptProc = windows.NewCallback(func(hwnd windows.HWND, msg uintptr, wparam uintptr, lparam uintptr) uintptr {
+    switch msg {
+        case wmInitDialog:
+            procSetDlgItemTextW.Call(uintptr(hwnd), idEdit, uintptr(unsafe.Pointer(&promptState.defaultValue[0])))
+            if promptState.timeout > 0 {
+                procSetTimer.Call(uintptr(hwnd), idTimer, promptState.timeout, 0)
+            }
+            return 1
+
+        case wmTimer:
+            if wparam == idTimer {
+                procKillTimer.Call(uintptr(hwnd), idTimer)
+                procEndDialog.Call(uintptr(hwnd), idTimeout)
+                return 1
+            }
+
+        case wmCommand:
+            switch wparam & 0xFFFF {
+                case idOk:
+                    var edit, _, _ = procGetDlgItem.Call(uintptr(hwnd), idEdit)
+                    var length, _, _ = procGetWindowTextLengthW.Call(edit)
+                    var buf = make([]uint16, length + 1)
+                    procGetDlgItemTextW.Call(uintptr(hwnd), idEdit, uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+                    promptState.result = windows.UTF16ToString(buf)
+                    procEndDialog.Call(uintptr(hwnd), uintptr(idOk))
+                    return 1
+
+                case uintptr(idCancel):
+                    procEndDialog.Call(uintptr(hwnd), uintptr(idCancel))
+                    return 1
+            }
+    }
+    return 0
+})
+
+// dlgTemplate builds a DLGTEMPLATE and its DLGITEMTEMPLATE items as a sequence
+// of WORDs.
+type dlgTemplate struct {
+    buf []uint16
+    count int
+}
+
+func (t *dlgTemplate) dword(x uint32) {
+    t.buf = append(t.buf, uint16(x & 0xFFFF), uint16(x >> 16))
+}
+
+func (t *dlgTemplate) str(s string) {
+    var w = toWideChar(s)
+    if w[len(w)-1] != 0 { w = append(w, 0) }
+    t.buf = append(t.buf, w...)
+}
+
+func (t *dlgTemplate) align() {
+    if len(t.buf) % 2 != 0 { t.buf = append(t.buf, 0) }
+}
+
+func (t *dlgTemplate) item(style uint32, x, y, cx, cy int16, id uint16, class uint16, text string) {
+    t.align()
+    t.dword(style)
+    t.dword(0) // dwExtendedStyle
+    t.buf = append(t.buf, uint16(x), uint16(y), uint16(cx), uint16(cy), id, 0xFFFF, class)
+    t.str(text)
+    t.buf = append(t.buf, 0) // no creation data
+    t.count++
+}
+
+func promptTemplate(title string, message string, hidden bool) []uint16 {
+    var t = &dlgTemplate{buf: make([]uint16, 0, 256)}
+
+    t.dword(dsSetFont | dsModalFrame | dsSetForeground | dsCenter | wsPopup | wsCaption | wsSysMenu)
+    t.dword(0) // dwExtendedStyle
+    t.buf = append(t.buf, 0, 0, 0, 200, 76) // cdit (set below), x, y, cx, cy
+    t.buf = append(t.buf, 0, 0) // no menu, default class
+    t.str(title)
+    t.buf = append(t.buf, 8) // font point size
+    t.str("MS Shell Dlg")
+
+    var editStyle uint32 = wsChild | wsVisible | wsBorder | wsTabStop | esAutoHScroll
+    if hidden { editStyle |= esPassword }
+
+    t.item(wsChild | wsVisible, 7, 7, 186, 26, idText, classStatic, message)
+    t.item(editStyle, 7, 36, 186, 12, idEdit, classEdit, "")
+    t.item(wsChild | wsVisible | wsTabStop | bsDefPushButton, 89, 55, 50, 14, idOk, classButton, "OK")
+    t.item(wsChild | wsVisible | wsTabStop | bsPushButton, 143, 55, 50, 14, uint16(idCancel), classButton, "Cancel")
+
+    t.buf[4] = uint16(t.count)
+    return t.buf
+}
+
+func windowsPrompt(req *Request) (string, bool, error) {
+    promptState.Lock()
+    defer promptState.Unlock()
+
+    var template = promptTemplate(req.Title, req.Message, req.Kind == KindPassword)
+    promptState.defaultValue = toWideChar(req.Default)
+    promptState.timeout = uintptr(req.Timeout.Milliseconds())
+    promptState.result = ""
+
+    var result, _, err = procDialogBoxIndirectParamW.Call(
+        0,
+        uintptr(unsafe.Pointer(&template[0])),
+        0,
+        req.Parent,
+        promptProc,
+        0,
+    )
+
+    // DialogBoxIndirectParamW returns 0 or -1 on failure, and otherwise the
+    // result passed to EndDialog
+    switch int32(result) {
+        case idOk:      return promptState.result, true, nil
+        case idCancel:  return "", false, nil
+        case idTimeout: return "", false, ErrTimeout
+        case 0, -1:     return "", false, callError("DialogBoxIndirectParamW", err)
+        default:        return "", false, fmt.Errorf("dialog: unexpected prompt result %d", int32(result))
+    }
+}
@@ -0,0 +1,373 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "errors"
+    "image/color"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "tawesoft.co.uk/go/dialog/internal/hooks"
+)
+
+// Kind identifies a kind of dialog.
+type Kind int
+
+const (
+    KindAlert Kind = iota
+    KindConfirm
+    KindQuestion
+    KindPrompt
+    KindPassword
+    KindOpenFile
+    KindOpenFiles
+    KindSaveFile
+    KindSelectFolder
+    KindNotify
+    KindChoose
+    KindChooseMany
+    KindProgress
+    KindPickDate
+    KindPickColor
+    KindPickNumber
+)
+
+// String returns a human-readable name for the kind e.g. "Alert".
+func (k Kind) String() string {
+    switch k {
+        case KindAlert:        return "Alert"
+        case KindConfirm:      return "Confirm"
+        case KindQuestion:     return "Question"
+        case KindPrompt:       return "Prompt"
+        case KindPassword:     return "Password"
+        case KindOpenFile:     return "OpenFile"
+        case KindOpenFiles:    return "OpenFiles"
+        case KindSaveFile:     return "SaveFile"
+        case KindSelectFolder: return "SelectFolder"
+        case KindNotify:       return "Notify"
+        case KindChoose:       return "Choose"
+        case KindChooseMany:   return "ChooseMany"
+        case KindProgress:     return "Progress"
+        case KindPickDate:     return "PickDate"
+        case KindPickColor:    return "PickColor"
+        case KindPickNumber:   return "PickNumber"
+        default:               return "Kind(?)"
+    }
+}
+
+// isFile returns true for file and folder dialogs.
+func (k Kind) isFile() bool {
+    switch k {
+        case KindOpenFile, KindOpenFiles, KindSaveFile, KindSelectFolder:
+            return true
+        default:
+            return false
+    }
+}
+
+// Request describes a dialog to be displayed by a Provider, which should
+// honour the embedded Options where it can. The embedded Options always
+// include a non-empty Title.
+type Request struct {
+    Kind Kind
+    Message string // already formatted, if it was a printf-style string
+    Options
+
+    // Default is the initial text for KindPrompt, or the default filename
+    // for KindSaveFile.
+    Default string
+
+    // Directory is the starting directory for file and folder dialogs.
+    Directory string
+
+    // Filters restrict the files shown by file dialogs.
+    Filters []Filter
+
+    // Items are the choices for KindChoose and KindChooseMany.
+    Items []string
+
+    // Date is the initial date for KindPickDate.
+    Date time.Time
+
+    // Color is the initial colour for KindPickColor.
+    Color color.RGBA
+
+    // Number is the initial value for KindPickNumber, which is always between
+    // Min and Max inclusive, and a whole number of Steps from Min. Step is
+    // always at least 1.
+    Number, Min, Max, Step int
+}
+
+// PlainMessage returns the message followed by any details, for providers
+// that can't display the details separately.
+func (r *Request) PlainMessage() string {
+    if len(r.Details) == 0 { return r.Message }
+    return r.Message + "\n\nDetails:\n\n" + r.Details
+}
+
+// Response is the result of a dialog displayed by a Provider.
+type Response struct {
+    Answer Answer // for KindConfirm (Yes or No) and KindQuestion
+    Text string // for KindPrompt and KindPassword
+    Paths []string // for file and folder dialogs
+    Choices []int // for KindChoose (at most one) and KindChooseMany, indexes into Request.Items
+    Date time.Time // for KindPickDate
+    Color color.RGBA // for KindPickColor
+    Number int // for KindPickNumber
+}
+
+// Provider is a way of displaying dialogs, such as a native API, an external
+// program like zenity, or a terminal.
+//
+// Show displays a dialog and waits for the user to respond to it. A provider
+// returns ErrUnsupported for any kind of dialog it does not support, and
+// ErrCancelled if the user dismissed the dialog without answering it. For any
+// other error, the next provider in order of preference is tried instead.
+type Provider interface {
+    Name() string
+    Show(ctx context.Context, req *Request) (Response, error)
+}
+
+// EnvProviders is the name of an environment variable that, if set, selects
+// registered providers by name, in order of preference, separated by commas.
+// For example, TAWESOFT_DIALOG=stdio forces dialogs to use stdin and stderr.
+const EnvProviders = "TAWESOFT_DIALOG"
+
+var errNotRegistered = errors.New("dialog: no provider registered with that name")
+
+// registry holds providers added by Register or SetProviders, followed by
+// the providers built in for the current platform. The built-in providers
+// aren't discovered until they are first needed.
+var registry struct {
+    sync.Mutex
+    providers []Provider
+    builtin []Provider
+    discovered bool
+}
+
+// discover returns the built-in providers for the current platform, in order
+// of preference. It is replaced by each platform.
+var discover = func() []Provider {
+    return []Provider{stdioProvider{}}
+}
+
+// builtinProviders returns the built-in providers, discovering them if
+// necessary. The registry must be locked.
+func builtinProviders() []Provider {
+    if !registry.discovered {
+        registry.builtin = discover()
+        registry.discovered = true
+    }
+    return registry.builtin
+}
+
+// Register adds a provider with the highest order of preference, ahead of
+// any providers already registered.
+func Register(p Provider) {
+    registry.Lock()
+    defer registry.Unlock()
+
+    registry.providers = append([]Provider{p}, registry.providers...)
+}
+
+// SetProviders replaces all registered providers, including the built-in
+// providers, with the given providers, in order of preference.
+func SetProviders(providers ...Provider) {
+    registry.Lock()
+    defer registry.Unlock()
+
+    registry.providers = append([]Provider(nil), providers...)
+    registry.builtin = nil
+    registry.discovered = true
+}
+
+// saveProviders saves the registered providers and the EnvProviders
+// environment variable, and returns a function that restores them exactly,
+// for example at the end of a test. Unlike SetProviders, this doesn't stop
+// the built-in providers being discovered when first needed.
+func saveProviders() (restore func()) {
+    registry.Lock()
+    var providers, builtin, discovered = registry.providers, registry.builtin, registry.discovered
+    registry.Unlock()
+
+    var env, hasEnv = os.LookupEnv(EnvProviders)
+
+    return func() {
+        registry.Lock()
+        registry.providers, registry.builtin, registry.discovered = providers, builtin, discovered
+        registry.Unlock()
+
+        if hasEnv {
+            os.Setenv(EnvProviders, env)
+        } else {
+            os.Unsetenv(EnvProviders)
+        }
+    }
+}
+
+func init() {
+    hooks.SaveProviders = saveProviders
+}
+
+// Providers returns all registered providers, in order of preference. Unless
+// replaced by SetProviders, this includes providers built in for the current
+// platform, such as "zenity", "xmessage" and "stdio" on Linux.
+func Providers() []Provider {
+    registry.Lock()
+    defer registry.Unlock()
+
+    var providers = append([]Provider(nil), registry.providers...)
+    return append(providers, builtinProviders()...)
+}
+
+// Rediscover finds the built-in providers for the current platform again,
+// for example after $PATH has changed or a program has been installed, and
+// replaces any found before (or removed by SetProviders). Providers added by
+// Register or SetProviders are kept.
+func Rediscover() {
+    registry.Lock()
+    registry.discovered = false
+    registry.Unlock()
+
+    versions.Lock()
+    versions.cache = nil
+    versions.Unlock()
+}
+
+// Backend describes a built-in provider found on this system.
+type Backend struct {
+    Name string // name of the provider e.g. "zenity"
+    Path string // path to the program it runs, if any
+    Version string // version of the program or system, if known
+}
+
+// versions caches the Backend for each built-in provider, which can be slow
+// to find because it may mean running a program.
+var versions struct {
+    sync.Mutex
+    cache map[string]Backend
+}
+
+// describe returns a Backend for a built-in provider. It is replaced by each
+// platform.
+var describe = func(p Provider) Backend {
+    return Backend{Name: p.Name()}
+}
+
+// Available returns the built-in providers found on this system, in order of
+// preference, with their versions where known. Results are cached until the
+// next call to Rediscover.
+//
+// The first call may run each program found to ask for its version.
+func Available() []Backend {
+    registry.Lock()
+    var providers = append([]Provider(nil), builtinProviders()...)
+    registry.Unlock()
+
+    versions.Lock()
+    defer versions.Unlock()
+    if versions.cache == nil { versions.cache = make(map[string]Backend) }
+
+    var backends = make([]Backend, 0, len(providers))
+    for _, p := range providers {
+        var b, ok = versions.cache[p.Name()]
+        if !ok {
+            b = describe(p)
+            versions.cache[p.Name()] = b
+        }
+        backends = append(backends, b)
+    }
+    return backends
+}
+
+// selectProviders returns the providers to try, in order of preference,
+// taking into account the EnvProviders environment variable. Any names that
+// don't match a registered provider are returned as failures.
+func selectProviders() ([]Provider, []Failure) {
+    var providers = Providers()
+
+    var env = strings.TrimSpace(os.Getenv(EnvProviders))
+    if len(env) == 0 { return providers, nil }
+
+    var selected = make([]Provider, 0, len(providers))
+    var failures []Failure
+
+    for _, name := range strings.Split(env, ",") {
+        name = strings.TrimSpace(name)
+        if len(name) == 0 { continue }
+
+        var found = false
+        for _, p := range providers {
+            if p.Name() != name { continue }
+            selected = append(selected, p)
+            found = true
+            break
+        }
+
+        if !found {
+            failures = append(failures, Failure{Provider: name, Err: errNotRegistered})
+        }
+    }
+
+    return selected, failures
+}
+
+// modal is held while a modal dialog is displayed, so that dialogs shown by
+// several goroutines at once are displayed one at a time.
+var modal = make(chan struct{}, 1)
+
+// show tries each provider in order of preference until one is able to
+// display a dialog, or the user cancels it.
+//
+// Modal dialogs wait for any other modal dialog to be closed first. If ctx is
+// done while waiting, or while the dialog is displayed, show returns
+// ctx.Err() without trying any other providers.
+//
+// If the request has a timeout, the context passed to each provider has a
+// deadline, and a provider that stops after the deadline is taken to have
+// timed out, for example because the process it started was killed.
+func show(ctx context.Context, req *Request) (Response, Report, error) {
+    var providers, failures = selectProviders()
+    var report = Report{Failures: failures}
+
+    if req.Kind != KindNotify {
+        select {
+            case modal <- struct{}{}:
+                defer func() { <-modal }()
+            case <-ctx.Done():
+                return Response{}, report, ctx.Err()
+        }
+    }
+
+    if err := ctx.Err(); err != nil {
+        return Response{}, report, err
+    }
+
+    // for a notification, the timeout is how long it is shown for, and is left
+    // to the provider
+    var parent = ctx
+    if (req.Timeout > 0) && (req.Kind != KindNotify) {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+        defer cancel()
+    }
+
+    for _, p := range providers {
+        var response, err = p.Show(ctx, req)
+        if parent.Err() != nil {
+            report.Provider = p.Name()
+            return Response{}, report, parent.Err()
+        } else if (err != nil) && (ctx.Err() == context.DeadlineExceeded) {
+            err = ErrTimeout
+        }
+        if (err == nil) || errors.Is(err, ErrCancelled) {
+            report.Provider = p.Name()
+            return response, report, err
+        }
+        report.Failures = append(report.Failures, Failure{Provider: p.Name(), Err: err})
+    }
+
+    return Response{}, report, &ProviderError{Failures: report.Failures}
+}
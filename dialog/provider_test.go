@@ -0,0 +1,205 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "reflect"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+)
+
+type testProvider struct {
+    name string
+    kinds []Kind
+    response Response
+    err error
+}
+
+func (p testProvider) Name() string { return p.name }
+
+func (p testProvider) Show(ctx context.Context, req *Request) (Response, error) {
+    for _, k := range p.kinds {
+        if k == req.Kind { return p.response, p.err }
+    }
+    return Response{}, ErrUnsupported
+}
+
+// useProviders replaces every provider with the given providers, and clears
+// EnvProviders, until the test ends.
+func useProviders(t *testing.T, providers ...Provider) {
+    t.Cleanup(saveProviders())
+    os.Unsetenv(EnvProviders)
+    SetProviders(providers...)
+}
+
+func TestShow(t *testing.T) {
+    useProviders(t)
+
+    var errBroken = errors.New("broken")
+    var broken = testProvider{name: "broken", kinds: []Kind{KindAlert}, err: errBroken}
+    var working = testProvider{name: "working", kinds: []Kind{KindAlert, KindConfirm}}
+    var confirmOnly = testProvider{name: "confirm", kinds: []Kind{KindConfirm}, err: ErrCancelled}
+
+    SetProviders(working)
+    Register(confirmOnly)
+    Register(broken)
+
+    var report, err = AlertE("Message")
+    if err != nil {
+        t.Errorf("unexpected error: %v", err)
+    }
+    if report.Provider != "working" {
+        t.Errorf("expected working provider, got %q", report.Provider)
+    }
+    if (len(report.Failures) != 2) ||
+        (report.Failures[0].Err != errBroken) ||
+        (report.Failures[1].Err != ErrUnsupported) {
+        t.Errorf("unexpected failures: %+v", report.Failures)
+    }
+
+    if _, err = Confirm("Message"); err != ErrCancelled {
+        t.Errorf("expected cancellation, got %v", err)
+    }
+
+    os.Setenv(EnvProviders, "missing, working")
+    if _, err = Confirm("Message"); err != nil {
+        t.Errorf("expected working provider to be selected, got %v", err)
+    }
+
+    os.Unsetenv(EnvProviders)
+    SetProviders(broken)
+    report, err = AlertE("Message")
+    if !errors.Is(err, ErrNoProvider) {
+        t.Errorf("expected ErrNoProvider, got %v", err)
+    }
+    if perr, ok := err.(*ProviderError); !ok || (len(perr.Failures) != 1) {
+        t.Errorf("expected a *ProviderError with one failure, got %#v", err)
+    }
+}
+
+// blockingProvider waits until its context is done, counting how many
+// dialogs it is displaying at once.
+type blockingProvider struct {
+    mutex *sync.Mutex
+    current, max *int
+}
+
+func (p blockingProvider) Name() string { return "blocking" }
+
+func (p blockingProvider) Show(ctx context.Context, req *Request) (Response, error) {
+    p.mutex.Lock()
+    *p.current++
+    if *p.current > *p.max { *p.max = *p.current }
+    p.mutex.Unlock()
+
+    <-ctx.Done()
+
+    p.mutex.Lock()
+    *p.current--
+    p.mutex.Unlock()
+    return Response{}, errors.New("killed")
+}
+
+func TestShowContext(t *testing.T) {
+    useProviders(t)
+
+    var current, max int
+    SetProviders(
+        blockingProvider{mutex: &sync.Mutex{}, current: &current, max: &max},
+        testProvider{name: "working", kinds: []Kind{KindAlert}},
+    )
+
+    var ctx, cancel = context.WithTimeout(context.Background(), 50 * time.Millisecond)
+    defer cancel()
+
+    var wg sync.WaitGroup
+    var errs = make([]error, 3)
+    for i := 0; i < len(errs); i++ {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            errs[i] = AlertContext(ctx, "Message")
+        }(i)
+    }
+    wg.Wait()
+
+    for i, err := range errs {
+        if err != context.DeadlineExceeded {
+            t.Errorf("%d: expected context.DeadlineExceeded, got %v", i, err)
+        }
+    }
+    if max != 1 {
+        t.Errorf("expected dialogs to be displayed one at a time, got %d at once", max)
+    }
+
+    // a timeout from the options, rather than the context, is not an error
+    if err := (Options{Timeout: 10 * time.Millisecond}).Alert("Message"); err != nil {
+        t.Errorf("unexpected error: %v", err)
+    }
+    if _, err := (Options{Timeout: 10 * time.Millisecond}).Confirm("Message"); err != ErrTimeout {
+        t.Errorf("expected ErrTimeout, got %v", err)
+    }
+}
+
+func TestDiscover(t *testing.T) {
+    var restore = saveProviders()
+    registry.Lock()
+    registry.providers, registry.builtin, registry.discovered = nil, nil, false
+    registry.Unlock()
+
+    var savedDiscover, savedDescribe = discover, describe
+    defer func() {
+        restore()
+        discover, describe = savedDiscover, savedDescribe
+        Rediscover()
+    }()
+
+    var count int
+    discover = func() []Provider {
+        count++
+        return []Provider{testProvider{name: "builtin"}}
+    }
+    describe = func(p Provider) Backend {
+        return Backend{Name: p.Name(), Version: fmt.Sprintf("%d", count)}
+    }
+
+    var names = func() string {
+        var names = make([]string, 0)
+        for _, p := range Providers() { names = append(names, p.Name()) }
+        return strings.Join(names, " ")
+    }
+
+    Register(testProvider{name: "registered"})
+    if count != 0 {
+        t.Errorf("expected built-in providers not to be discovered yet")
+    }
+    if result := names(); result != "registered builtin" {
+        t.Errorf("got providers %q", result)
+    }
+    if result := Available(); !reflect.DeepEqual(result, []Backend{{Name: "builtin", Version: "1"}}) {
+        t.Errorf("got backends %+v", result)
+    }
+
+    Providers()
+    Available()
+    if count != 1 {
+        t.Errorf("expected built-in providers to be discovered once, got %d", count)
+    }
+
+    Rediscover()
+    if result := names(); result != "registered builtin" {
+        t.Errorf("got providers %q after Rediscover", result)
+    }
+    if result := Available(); !reflect.DeepEqual(result, []Backend{{Name: "builtin", Version: "2"}}) {
+        t.Errorf("got backends %+v after Rediscover", result)
+    }
+
+    SetProviders(testProvider{name: "only"})
+    if result := names(); result != "only" {
+        t.Errorf("got providers %q after SetProviders", result)
+    }
+}
@@ -0,0 +1,26 @@
+// +build linux
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "os"
+
+    "golang.org/x/sys/unix"
+)
+
+// disableEcho stops a terminal from echoing typed input, for example while
+// entering a password, and returns a function that restores the previous
+// state. It does nothing if f is not a terminal.
+func disableEcho(f *os.File) (restore func()) {
+    var fd = int(f.Fd())
+
+    var termios, err = unix.IoctlGetTermios(fd, unix.TCGETS)
+    if err != nil { return func() {} }
+
+    var previous = *termios
+    termios.Lflag &^= unix.ECHO
+    termios.Lflag |= unix.ICANON | unix.ISIG
+    if unix.IoctlSetTermios(fd, unix.TCSETS, termios) != nil { return func() {} }
+
+    return func() { unix.IoctlSetTermios(fd, unix.TCSETS, &previous) }
+}
@@ -0,0 +1,26 @@
+// +build windows
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "os"
+
+    "golang.org/x/sys/windows"
+)
+
+// disableEcho stops a console from echoing typed input, for example while
+// entering a password, and returns a function that restores the previous
+// state. It does nothing if f is not a console.
+func disableEcho(f *os.File) (restore func()) {
+    var handle = windows.Handle(f.Fd())
+
+    var mode uint32
+    if windows.GetConsoleMode(handle, &mode) != nil { return func() {} }
+
+    var previous = mode
+    mode &^= windows.ENABLE_ECHO_INPUT
+    mode |= windows.ENABLE_LINE_INPUT | windows.ENABLE_PROCESSED_INPUT
+    if windows.SetConsoleMode(handle, mode) != nil { return func() {} }
+
+    return func() { windows.SetConsoleMode(handle, previous) }
+}
@@ -0,0 +1,395 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strings"
+    "sync"
+)
+
+// stdioProvider displays dialogs as text on stderr and reads any response
+// from stdin. It is available on every platform as a last resort.
+type stdioProvider struct{}
+
+// stdioColumns is the width that messages are wrapped to, which fits a
+// typical terminal.
+const stdioColumns = 78
+
+func (stdioProvider) Name() string { return "stdio" }
+
+func (stdioProvider) Show(ctx context.Context, req *Request) (Response, error) {
+    var in = stdin.context(ctx)
+
+    var wrapped = *req
+    wrapped.Message = strings.Join(wrapLines(req.Message, stdioColumns), "\n")
+    req = &wrapped
+
+    switch req.Kind {
+        case KindAlert:
+            fmt.Fprintf(os.Stderr, "\n===[%s]===\n\n", req.Title)
+            fmt.Fprint(os.Stderr, stdioLabel(req.Level), req.PlainMessage())
+            fmt.Fprintf(os.Stdout, "\n\n=========\n\n")
+            return Response{}, nil
+
+        case KindNotify:
+            fmt.Fprintf(os.Stderr, "\n===[%s]===\n\n%s%s\n\n=========\n\n", req.Title, stdioLabel(req.Level), req.Message)
+            return Response{}, nil
+
+        case KindConfirm, KindQuestion:
+            var answer, err = stdioAsk(in, os.Stderr, req.Title, req.PlainMessage(), req.Kind == KindQuestion, req.DefaultButton)
+            return Response{Answer: answer}, err
+
+        case KindPrompt, KindPassword:
+            if req.Kind == KindPassword {
+                var restore = disableEcho(os.Stdin)
+                defer restore()
+            }
+            var text, ok, err = stdioPrompt(in, os.Stderr, req.Title, req.Message, req.Default)
+            if (err == nil) && !ok { err = ErrCancelled }
+            return Response{Text: text}, err
+
+        case KindOpenFile, KindOpenFiles, KindSaveFile, KindSelectFolder:
+            var paths, ok, err = stdioFile(in, os.Stderr, req)
+            if (err == nil) && !ok { err = ErrCancelled }
+            return Response{Paths: paths}, err
+
+        case KindChoose, KindChooseMany:
+            var choices, err = stdioChoose(in, os.Stderr, req.Title, req.Message, req.Items, req.Kind == KindChooseMany)
+            return Response{Choices: choices}, err
+
+        case KindPickDate, KindPickColor, KindPickNumber:
+            return stdioPick(in, os.Stderr, req)
+
+        default:
+            return Response{}, ErrUnsupported
+    }
+}
+
+func (stdioProvider) ShowProgress(ctx context.Context, req *Request, cancel func()) (ProgressView, error) {
+    var p = &stdioProgress{out: os.Stderr, text: req.Message, pulse: -1, closed: make(chan struct{})}
+    fmt.Fprintf(p.out, "\n===[%s]===\n\n", req.Title)
+
+    go func() {
+        select {
+            case <-ctx.Done(): cancel()
+            case <-p.closed:
+        }
+    }()
+
+    return p, p.draw()
+}
+
+// stdioProgress is a progress bar on a single line of a terminal, redrawn
+// after each update.
+type stdioProgress struct {
+    out io.Writer
+    closed chan struct{}
+    text string
+    percent int
+    pulse int // position of the pulse, or -1 if showing a percentage
+    length int // length of the last line drawn
+}
+
+// stdioProgressWidth is the width of a progress bar, excluding its brackets.
+const stdioProgressWidth = 30
+
+// stdioProgressBar returns a progress bar e.g. "[#####-----]" showing a
+// percentage complete, or if pulse is not negative, a block that moves back
+// and forth as pulse increases.
+func stdioProgressBar(percent int, pulse int, width int) string {
+    var bar = []byte(strings.Repeat("-", width))
+
+    if pulse < 0 {
+        for i := 0; i < (percent * width) / 100; i++ { bar[i] = '#' }
+    } else {
+        const block = 3
+        var position = pulse % (2 * (width - block))
+        if position > width - block { position = 2 * (width - block) - position }
+        copy(bar[position:], "###")
+    }
+
+    return "[" + string(bar) + "]"
+}
+
+func (p *stdioProgress) draw() error {
+    var line = stdioProgressBar(p.percent, p.pulse, stdioProgressWidth)
+    if p.pulse < 0 {
+        line += fmt.Sprintf(" %3d%%", p.percent)
+    }
+    if len(p.text) > 0 {
+        line += " " + p.text
+    }
+
+    // overwrite anything left over from a longer line
+    var padding = p.length - len(line)
+    if padding < 0 { padding = 0 }
+    p.length = len(line)
+
+    var _, err = fmt.Fprintf(p.out, "\r%s%s", line, strings.Repeat(" ", padding))
+    return err
+}
+
+func (p *stdioProgress) SetPercent(percent int) error {
+    p.percent, p.pulse = percent, -1
+    return p.draw()
+}
+
+func (p *stdioProgress) SetText(text string) error {
+    p.text = text
+    return p.draw()
+}
+
+func (p *stdioProgress) Pulse() error {
+    p.pulse++
+    return p.draw()
+}
+
+func (p *stdioProgress) Close() error {
+    close(p.closed)
+    var _, err = fmt.Fprintf(p.out, "\n\n=========\n\n")
+    return err
+}
+
+// stdioLabel returns a prefix for a message that describes its level.
+func stdioLabel(level Level) string {
+    switch level {
+        case LevelInfo:    return "INFO: "
+        case LevelWarning: return "WARNING: "
+        case LevelError:   return "ERROR: "
+        default:           return ""
+    }
+}
+
+// stdin is shared so that buffered input isn't lost between dialogs.
+var stdin = newStdioReader(os.Stdin)
+
+// lineReader reads typed input a line at a time, like a bufio.Reader.
+type lineReader interface {
+    ReadString(delim byte) (string, error)
+}
+
+// stdioReader reads lines in the background, so that a dialog can stop
+// waiting for input when its context is done. A line that is read after a
+// dialog stops waiting is kept for the next dialog.
+type stdioReader struct {
+    in *bufio.Reader
+    mutex sync.Mutex
+    pending chan stdioLine // the result of a read in progress, or nil
+}
+
+type stdioLine struct {
+    line string
+    err error
+}
+
+func newStdioReader(in io.Reader) *stdioReader {
+    return &stdioReader{in: bufio.NewReader(in)}
+}
+
+// context returns a lineReader that returns ctx.Err() if ctx is done before
+// a line is read.
+func (r *stdioReader) context(ctx context.Context) lineReader {
+    return stdioContextReader{r: r, ctx: ctx}
+}
+
+type stdioContextReader struct {
+    r *stdioReader
+    ctx context.Context
+}
+
+func (c stdioContextReader) ReadString(delim byte) (string, error) {
+    var r = c.r
+
+    r.mutex.Lock()
+    if r.pending == nil {
+        var pending = make(chan stdioLine, 1)
+        r.pending = pending
+        go func() {
+            var line, err = r.in.ReadString(delim)
+            pending <- stdioLine{line, err}
+        }()
+    }
+    var pending = r.pending
+    r.mutex.Unlock()
+
+    select {
+        case result := <-pending:
+            r.mutex.Lock()
+            r.pending = nil
+            r.mutex.Unlock()
+            return result.line, result.err
+        case <-c.ctx.Done():
+            return "", c.ctx.Err()
+    }
+}
+
+// stdioAsk writes a message to out and reads a yes/no (and, optionally,
+// cancel) answer from in, asking again until it gets a valid answer. An empty
+// line means defaultAnswer, if not zero. If in is closed first, it returns
+// ErrCancelled.
+func stdioAsk(in lineReader, out io.Writer, title string, message string, allowCancel bool, defaultAnswer Answer) (Answer, error) {
+    var choices = []string{"y", "n"}
+    if allowCancel { choices = append(choices, "c") }
+    for i, choice := range choices {
+        if parsed, _ := parseAnswer(choice, true); parsed == defaultAnswer {
+            choices[i] = strings.ToUpper(choice)
+        }
+    }
+
+    fmt.Fprintf(out, "\n===[%s]===\n\n%s\n\n", title, message)
+
+    for {
+        fmt.Fprintf(out, "[%s]: ", strings.Join(choices, "/"))
+
+        var line, err = in.ReadString('\n')
+        if (defaultAnswer != 0) && (len(strings.TrimSpace(line)) == 0) && (err == nil) {
+            fmt.Fprintf(out, "\n=========\n\n")
+            return defaultAnswer, nil
+        }
+        if answer, ok := parseAnswer(line, allowCancel); ok {
+            fmt.Fprintf(out, "\n=========\n\n")
+            return answer, nil
+        }
+        if err == io.EOF { return 0, ErrCancelled }
+        if err != nil { return 0, err }
+    }
+}
+
+// stdioPrompt writes a message to out and reads a line of text from in. An
+// empty line is taken to mean defaultValue. If in is closed before a line is
+// read, the prompt is treated as cancelled.
+func stdioPrompt(in lineReader, out io.Writer, title string, message string, defaultValue string) (string, bool, error) {
+    fmt.Fprintf(out, "\n===[%s]===\n\n%s\n\n", title, message)
+    if len(defaultValue) > 0 {
+        fmt.Fprintf(out, "[%s]: ", defaultValue)
+    } else {
+        fmt.Fprintf(out, ": ")
+    }
+
+    var line, err = in.ReadString('\n')
+    fmt.Fprintf(out, "\n=========\n\n")
+
+    if err == io.EOF {
+        if len(line) == 0 { return "", false, nil }
+    } else if err != nil {
+        return "", false, err
+    }
+
+    line = strings.TrimRight(line, "\r\n")
+    if len(line) == 0 { line = defaultValue }
+    return line, true, nil
+}
+
+// stdioFile asks for one or more paths to be typed in, relative to directory.
+// For KindOpenFiles, each path is read on its own line until an empty line.
+func stdioFile(in lineReader, out io.Writer, req *Request) ([]string, bool, error) {
+    var message string
+    switch req.Kind {
+        case KindOpenFile:     message = "Enter the path of a file to open"
+        case KindOpenFiles:    message = "Enter the paths of files to open, one per line, then an empty line"
+        case KindSaveFile:     message = "Enter the path to save a file"
+        case KindSelectFolder: message = "Enter the path of a directory"
+    }
+
+    if len(req.Filters) > 0 {
+        var names = make([]string, 0, len(req.Filters))
+        for _, f := range req.Filters {
+            names = append(names, fmt.Sprintf("%s (%s)", f.Name, strings.Join(f.Patterns, ";")))
+        }
+        message += "\nFile types: " + strings.Join(names, ", ")
+    }
+
+    var results = make([]string, 0, 1)
+
+    for {
+        var defaultValue = ""
+        if len(results) == 0 { defaultValue = req.Default }
+
+        var line, ok, err = stdioPrompt(in, out, req.Title, message, defaultValue)
+        if err != nil { return nil, false, err }
+        if !ok { break }
+        if len(line) == 0 { break }
+
+        if (len(req.Directory) > 0) && !filepath.IsAbs(line) {
+            line = filepath.Join(req.Directory, line)
+        }
+        results = append(results, line)
+
+        if req.Kind != KindOpenFiles { break }
+    }
+
+    if len(results) == 0 { return nil, false, nil }
+    return results, true, nil
+}
+
+// stdioChoose writes a message and a numbered list of items to out, and
+// reads the number of an item from in, or if many is true, any number of
+// item numbers separated by spaces or commas. It asks again until it gets a
+// valid answer. If in is closed first, it returns ErrCancelled.
+func stdioChoose(in lineReader, out io.Writer, title string, message string, items []string, many bool) ([]int, error) {
+    fmt.Fprintf(out, "\n===[%s]===\n\n%s\n\n", title, message)
+    for i, item := range items {
+        fmt.Fprintf(out, "%4d) %s\n", i + 1, item)
+    }
+    fmt.Fprintln(out)
+
+    for {
+        if many {
+            fmt.Fprintf(out, "[numbers separated by spaces]: ")
+        } else {
+            fmt.Fprintf(out, "[1-%d]: ", len(items))
+        }
+
+        var line, err = in.ReadString('\n')
+        if (err == io.EOF) && (len(line) == 0) { return nil, ErrCancelled }
+        if (err != nil) && (err != io.EOF) { return nil, err }
+
+        var choices, ok = parseChoices(line, len(items))
+        if ok && (many || (len(choices) == 1)) {
+            fmt.Fprintf(out, "\n=========\n\n")
+            return choices, nil
+        }
+        if err == io.EOF { return nil, ErrCancelled }
+    }
+}
+
+// stdioPick writes a message to out and reads a date, colour or number as
+// text from in, as described by pickText. An empty line is taken to mean the
+// initial value. It asks again until it gets a valid answer. If in is closed
+// first, it returns ErrCancelled.
+func stdioPick(in lineReader, out io.Writer, req *Request) (Response, error) {
+    var initial, hint = pickText(req)
+
+    fmt.Fprintf(out, "\n===[%s]===\n\n", req.Title)
+    if len(req.Message) > 0 { fmt.Fprintf(out, "%s\n\n", req.Message) }
+
+    for {
+        fmt.Fprintf(out, "%s [%s]: ", hint, initial)
+
+        var line, err = in.ReadString('\n')
+        if (err == io.EOF) && (len(line) == 0) { return Response{}, ErrCancelled }
+        if (err != nil) && (err != io.EOF) { return Response{}, err }
+
+        var response, perr = parsePick(req, line)
+        if perr == nil {
+            fmt.Fprintf(out, "\n=========\n\n")
+            return response, nil
+        }
+        fmt.Fprintf(out, "%v\n", perr)
+        if err == io.EOF { return Response{}, ErrCancelled }
+    }
+}
+
+// parseAnswer parses a typed answer such as "y" or "No" (case-insensitive).
+func parseAnswer(s string, allowCancel bool) (Answer, bool) {
+    switch strings.ToLower(strings.TrimSpace(s)) {
+        case "y", "yes":    return Yes, true
+        case "n", "no":     return No, true
+        case "c", "cancel": return Cancel, allowCancel
+        default:            return 0, false
+    }
+}
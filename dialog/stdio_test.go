@@ -0,0 +1,252 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestStdioAsk(t *testing.T) {
+    var tests = []struct{
+        input string
+        allowCancel bool
+        defaultAnswer Answer
+        expected Answer
+        isErr bool
+    }{
+        {"y\n",                 false, 0,      Yes,    false},
+        {"YES\n",               false, 0,      Yes,    false},
+        {" no \n",              false, 0,      No,     false},
+        {"n",                   false, 0,      No,     false}, // no trailing newline
+        {"maybe\nc\nn\n",       false, 0,      No,     false}, // cancel not allowed
+        {"maybe\nc\nn\n",       true,  0,      Cancel, false},
+        {"",                    true,  0,      0,      true},
+        {"maybe\n",             true,  0,      0,      true},
+        {"\ny\n",               false, 0,      Yes,    false}, // no default
+        {"\n",                  false, No,     No,     false},
+        {"  \n",                true,  Cancel, Cancel, false},
+        {"y\n",                 true,  Cancel, Yes,    false},
+        {"",                    false, No,     0,      true},
+    }
+
+    for index, test := range tests {
+        var in = bufio.NewReader(strings.NewReader(test.input))
+        var answer, err = stdioAsk(in, ioutil.Discard, "Title", "Message", test.allowCancel, test.defaultAnswer)
+        if (err != nil) != test.isErr {
+            t.Errorf("Test %d: unexpected error status: %v", index, err)
+        } else if answer != test.expected {
+            t.Errorf("Test %d: got %v but wanted %v", index, answer, test.expected)
+        }
+    }
+}
+
+func TestStdioPrompt(t *testing.T) {
+    var tests = []struct{
+        input string
+        defaultValue string
+        expected string
+        ok bool
+    }{
+        {"hello\n",         "",      "hello", true},
+        {"hello world\r\n", "",      "hello world", true},
+        {"hello",           "",      "hello", true}, // no trailing newline
+        {"\n",              "",      "", true},
+        {"\n",              "guest", "guest", true},
+        {"admin\n",         "guest", "admin", true},
+        {"",                "guest", "", false},
+    }
+
+    for index, test := range tests {
+        var in = bufio.NewReader(strings.NewReader(test.input))
+        var result, ok, err = stdioPrompt(in, ioutil.Discard, "Title", "Message", test.defaultValue)
+        if err != nil {
+            t.Errorf("Test %d: unexpected error: %v", index, err)
+        } else if (result != test.expected) || (ok != test.ok) {
+            t.Errorf("Test %d: got (%q, %t) but wanted (%q, %t)",
+                index, result, ok, test.expected, test.ok)
+        }
+    }
+}
+
+func TestStdioFile(t *testing.T) {
+    var tests = []struct{
+        input string
+        kind Kind
+        directory string
+        filename string
+        expected []string
+    }{
+        {"a.txt\n",              KindOpenFile,  "",     "",      []string{"a.txt"}},
+        {"a.txt\n",              KindOpenFile,  "/tmp", "",      []string{"/tmp/a.txt"}},
+        {"/etc/a.txt\n",         KindOpenFile,  "/tmp", "",      []string{"/etc/a.txt"}},
+        {"\n",                   KindSaveFile,  "/tmp", "b.txt", []string{"/tmp/b.txt"}},
+        {"\n",                   KindOpenFile,  "/tmp", "",      nil},
+        {"",                     KindOpenFile,  "/tmp", "",      nil},
+        {"a\nb\n\nc\n",          KindOpenFiles, "/tmp", "",      []string{"/tmp/a", "/tmp/b"}},
+        {"a\nb",                 KindOpenFiles, "",     "",      []string{"a", "b"}},
+    }
+
+    for index, test := range tests {
+        var in = bufio.NewReader(strings.NewReader(test.input))
+        var results, ok, err = stdioFile(in, ioutil.Discard, &Request{
+            Kind:      test.kind,
+            Options:   Options{Title: "Title"},
+            Default:   test.filename,
+            Directory: test.directory,
+        })
+        if err != nil {
+            t.Errorf("Test %d: unexpected error: %v", index, err)
+        } else if ok != (test.expected != nil) {
+            t.Errorf("Test %d: got ok=%t", index, ok)
+        } else if !reflect.DeepEqual(results, test.expected) {
+            t.Errorf("Test %d: got %v but wanted %v", index, results, test.expected)
+        }
+    }
+}
+
+func TestStdioChoose(t *testing.T) {
+    var items = []string{"apple", "banana", "cherry"}
+
+    var tests = []struct{
+        input string
+        many bool
+        expected []int
+        isErr bool
+    }{
+        {"2\n",          false, []int{1},       false},
+        {"3",            false, []int{2},       false}, // no trailing newline
+        {"4\nx\n1\n",    false, []int{0},       false}, // out of range, invalid
+        {"\n1 2\n3\n",   false, []int{2},       false}, // must be exactly one
+        {"",             false, nil,            true},
+        {"4\n",          false, nil,            true},
+        {"3 1\n",        true,  []int{0, 2},    false},
+        {"1,2,3\n",      true,  []int{0, 1, 2}, false},
+        {"\n",           true,  []int{},        false},
+        {"",             true,  nil,            true},
+    }
+
+    for index, test := range tests {
+        var in = bufio.NewReader(strings.NewReader(test.input))
+        var result, err = stdioChoose(in, ioutil.Discard, "Title", "Message", items, test.many)
+        if (err != nil) != test.isErr {
+            t.Errorf("Test %d: unexpected error status: %v", index, err)
+        } else if !reflect.DeepEqual(result, test.expected) {
+            t.Errorf("Test %d: got %v but wanted %v", index, result, test.expected)
+        }
+    }
+}
+
+func TestStdioPick(t *testing.T) {
+    var req = &Request{
+        Kind:    KindPickNumber,
+        Options: Options{Title: "Title"},
+        Min:     0,
+        Max:     100,
+        Step:    10,
+        Number:  50,
+    }
+
+    var tests = []struct{
+        input string
+        expected int
+        isErr bool
+    }{
+        {"20\n",           20, false},
+        {"\n",             50, false},
+        {"15\n200\n30\n", 30, false}, // not a step, out of range
+        {"70",             70, false}, // no trailing newline
+        {"x",              0,  true},
+        {"",               0,  true},
+    }
+
+    for index, test := range tests {
+        var in = bufio.NewReader(strings.NewReader(test.input))
+        var result, err = stdioPick(in, ioutil.Discard, req)
+        if (err != nil) != test.isErr {
+            t.Errorf("Test %d: unexpected error status: %v", index, err)
+        } else if result.Number != test.expected {
+            t.Errorf("Test %d: got %d but wanted %d", index, result.Number, test.expected)
+        }
+    }
+}
+
+func TestStdioContext(t *testing.T) {
+    var r, w, err = os.Pipe()
+    if err != nil { t.Fatal(err) }
+    defer r.Close()
+    defer w.Close()
+
+    var savedStdin, savedStderr = stdin, os.Stderr
+    defer func() { stdin, os.Stderr = savedStdin, savedStderr }()
+    stdin = newStdioReader(r)
+    os.Stderr, err = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+    if err != nil { t.Fatal(err) }
+    defer os.Stderr.Close()
+
+    // nothing is typed, so the prompt times out
+    var ctx, cancel = context.WithTimeout(context.Background(), 50 * time.Millisecond)
+    defer cancel()
+
+    var result = make(chan error, 1)
+    go func() {
+        var _, err = stdioProvider{}.Show(ctx, &Request{Kind: KindPrompt})
+        result <- err
+    }()
+
+    select {
+        case err := <-result:
+            if err != context.DeadlineExceeded { t.Errorf("got %v but wanted %v", err, context.DeadlineExceeded) }
+        case <-time.After(5 * time.Second):
+            t.Fatalf("prompt didn't time out")
+    }
+
+    // a line typed afterwards is read by the next prompt
+    fmt.Fprintln(w, "hello")
+    var response, rerr = stdioProvider{}.Show(context.Background(), &Request{Kind: KindPrompt})
+    if (rerr != nil) || (response.Text != "hello") {
+        t.Errorf("got %q, %v but wanted %q", response.Text, rerr, "hello")
+    }
+
+    // a progress dialog is cancelled when its context is done
+    ctx, cancel = context.WithCancel(context.Background())
+    var cancelled = make(chan struct{})
+    var view, perr = stdioProvider{}.ShowProgress(ctx, &Request{Kind: KindProgress}, func() { close(cancelled) })
+    if perr != nil { t.Fatal(perr) }
+    cancel()
+
+    select {
+        case <-cancelled:
+        case <-time.After(5 * time.Second): t.Errorf("progress dialog wasn't cancelled")
+    }
+    view.Close()
+}
+
+func TestStdioProgressBar(t *testing.T) {
+    var tests = []struct{
+        percent int
+        pulse int
+        expected string
+    }{
+        {0,   -1, "[----------]"},
+        {55,  -1, "[#####-----]"},
+        {100, -1, "[##########]"},
+        {0,   0,  "[###-------]"},
+        {0,   2,  "[--###-----]"},
+        {0,   7,  "[-------###]"},
+        {0,   8,  "[------###-]"},
+        {0,   14, "[###-------]"},
+    }
+
+    for index, test := range tests {
+        var result = stdioProgressBar(test.percent, test.pulse, 10)
+        if result != test.expected {
+            t.Errorf("Test %d: got %q but wanted %q", index, result, test.expected)
+        }
+    }
+}
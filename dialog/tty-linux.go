@@ -0,0 +1,104 @@
+// +build linux
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "os"
+
+    "golang.org/x/sys/unix"
+)
+
+var errNotTerminal = errors.New("dialog: stdin and stderr must be a terminal")
+
+// ttyProvider draws dialogs in the middle of a terminal using ANSI escape
+// sequences, with buttons that can be pressed using the keyboard or clicked
+// with a mouse. It is useful over SSH where there is no display.
+type ttyProvider struct{}
+
+func (ttyProvider) Name() string { return "tty" }
+
+func (ttyProvider) Show(ctx context.Context, req *Request) (Response, error) {
+    switch req.Kind {
+        case KindAlert, KindConfirm, KindQuestion, KindChoose, KindChooseMany:
+        default:
+            return Response{}, ErrUnsupported
+    }
+
+    var in, out = os.Stdin, os.Stderr
+    var restore, err = ttyRaw(in)
+    if err != nil { return Response{}, err }
+    defer restore()
+
+    if _, err := unix.IoctlGetTermios(int(out.Fd()), unix.TCGETS); err != nil {
+        return Response{}, errNotTerminal
+    }
+
+    // alternate screen, hide cursor, report mouse clicks
+    fmt.Fprint(out, "\x1b[?1049h\x1b[?25l\x1b[?1000h\x1b[?1006h")
+    defer fmt.Fprint(out, "\x1b[?1006l\x1b[?1000l\x1b[?25h\x1b[?1049l")
+
+    var d = newTTYDialog(req)
+    var reader = bufio.NewReader(ttyReader{ctx: ctx, f: in})
+
+    for !d.done {
+        var cols, rows = 80, 24
+        if size, err := unix.IoctlGetWinsize(int(out.Fd()), unix.TIOCGWINSZ); err == nil {
+            cols, rows = int(size.Col), int(size.Row)
+        }
+
+        if _, err := fmt.Fprint(out, d.render(cols, rows)); err != nil {
+            return Response{}, err
+        }
+
+        var event, err = readTTYEvent(reader)
+        if err != nil { return Response{}, err }
+        d.handle(event)
+    }
+
+    return d.response(req.Kind)
+}
+
+// ttyRaw puts a terminal into raw mode, where each read returns after at
+// most a tenth of a second, and returns a function that restores the previous
+// state.
+func ttyRaw(f *os.File) (restore func(), err error) {
+    var fd = int(f.Fd())
+
+    var termios, terr = unix.IoctlGetTermios(fd, unix.TCGETS)
+    if terr != nil { return nil, errNotTerminal }
+
+    var previous = *termios
+    termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP |
+        unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+    termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+    termios.Cflag &^= unix.CSIZE | unix.PARENB
+    termios.Cflag |= unix.CS8
+    termios.Cc[unix.VMIN] = 0
+    termios.Cc[unix.VTIME] = 1
+
+    if err := unix.IoctlSetTermios(fd, unix.TCSETS, termios); err != nil { return nil, err }
+    return func() { unix.IoctlSetTermios(fd, unix.TCSETS, &previous) }, nil
+}
+
+// ttyReader reads from a terminal in raw mode until there is some input, or
+// ctx is done.
+type ttyReader struct {
+    ctx context.Context
+    f *os.File
+}
+
+func (r ttyReader) Read(p []byte) (int, error) {
+    for {
+        var n, err = r.f.Read(p)
+        if n > 0 { return n, nil }
+
+        // a read that times out without any input returns io.EOF
+        if (err != nil) && (err != io.EOF) { return 0, err }
+        if err := r.ctx.Err(); err != nil { return 0, err }
+    }
+}
@@ -0,0 +1,367 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "bufio"
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// ttyKey identifies a key press, or a mouse click, read from a terminal.
+type ttyKey int
+
+const (
+    ttyKeyNone ttyKey = iota // ignored input
+    ttyKeyRune
+    ttyKeyEnter
+    ttyKeyEscape
+    ttyKeyInterrupt
+    ttyKeyTab
+    ttyKeyBacktab
+    ttyKeyUp
+    ttyKeyDown
+    ttyKeyLeft
+    ttyKeyRight
+    ttyKeyClick
+)
+
+// ttyEvent is a key press, or a mouse click at (x, y) counting from 1.
+type ttyEvent struct {
+    key ttyKey
+    r rune
+    x, y int
+}
+
+// readTTYEvent reads a key press or mouse click from a terminal in raw mode,
+// with SGR mouse reporting enabled.
+func readTTYEvent(in *bufio.Reader) (ttyEvent, error) {
+    var r, _, err = in.ReadRune()
+    if err != nil { return ttyEvent{}, err }
+
+    switch r {
+        case '\r', '\n': return ttyEvent{key: ttyKeyEnter}, nil
+        case '\t':       return ttyEvent{key: ttyKeyTab}, nil
+        case 3:          return ttyEvent{key: ttyKeyInterrupt}, nil // Ctrl-C
+        case 0x1b:       // escape sequence, or the escape key on its own
+        default:         return ttyEvent{key: ttyKeyRune, r: r}, nil
+    }
+
+    if in.Buffered() == 0 { return ttyEvent{key: ttyKeyEscape}, nil }
+
+    var introducer, _ = in.ReadByte()
+    if (introducer != '[') && (introducer != 'O') { return ttyEvent{key: ttyKeyEscape}, nil }
+
+    // a control sequence is parameter bytes followed by a final byte
+    var params []byte
+    for {
+        var b, err = in.ReadByte()
+        if err != nil { return ttyEvent{}, err }
+        if (b >= 0x40) && (b <= 0x7E) {
+            return parseTTYSequence(string(params), b), nil
+        }
+        params = append(params, b)
+    }
+}
+
+// parseTTYSequence interprets the parameters and final byte of a control
+// sequence.
+func parseTTYSequence(params string, final byte) ttyEvent {
+    switch final {
+        case 'A': return ttyEvent{key: ttyKeyUp}
+        case 'B': return ttyEvent{key: ttyKeyDown}
+        case 'C': return ttyEvent{key: ttyKeyRight}
+        case 'D': return ttyEvent{key: ttyKeyLeft}
+        case 'Z': return ttyEvent{key: ttyKeyBacktab}
+        case 'M': // SGR mouse press: "<button;x;y"
+            if !strings.HasPrefix(params, "<") { break }
+
+            var fields = strings.Split(params[1:], ";")
+            if len(fields) != 3 { break }
+
+            var button, err1 = strconv.Atoi(fields[0])
+            var x, err2 = strconv.Atoi(fields[1])
+            var y, err3 = strconv.Atoi(fields[2])
+            if (err1 != nil) || (err2 != nil) || (err3 != nil) { break }
+
+            // left button only, and not a drag
+            if (button & 3 != 0) || (button & 32 != 0) { break }
+            return ttyEvent{key: ttyKeyClick, x: x, y: y}
+    }
+    return ttyEvent{key: ttyKeyNone}
+}
+
+type ttyButton struct {
+    label string
+    answer Answer // or ttyDetails
+}
+
+// ttyDetails is the answer of a button that shows or hides the details,
+// instead of finishing the dialog.
+const ttyDetails Answer = -1
+
+// ttyHit is an area of the terminal that can be clicked: a button, or an
+// item in a list.
+type ttyHit struct {
+    y, x0, x1 int // x1 is exclusive
+    button int // or -1
+    item int // or -1
+}
+
+// ttyDialog is a dialog drawn in a box in the middle of a terminal by the
+// tty provider. It doesn't depend on any particular terminal, so that it can
+// be tested.
+type ttyDialog struct {
+    title string
+    message string
+    details string
+    expanded bool // details are shown
+    buttons []ttyButton
+    focus int // index of the focused button
+
+    items []string
+    many bool // any number of items can be checked
+    checked []bool
+    cursor int // index of the current item
+    offset int // index of the first item visible
+
+    hits []ttyHit // set by render
+
+    done bool
+    answer Answer // answer of the button pressed, or zero if dismissed
+}
+
+func newTTYDialog(req *Request) *ttyDialog {
+    var d = &ttyDialog{
+        title: req.Title,
+        message: req.Message,
+        items: req.Items,
+        many: req.Kind == KindChooseMany,
+        checked: make([]bool, len(req.Items)),
+    }
+
+    switch req.Kind {
+        case KindAlert:
+            d.buttons = []ttyButton{{"OK", Yes}}
+        case KindConfirm:
+            d.buttons = []ttyButton{{"Yes", Yes}, {"No", No}}
+        case KindQuestion:
+            d.buttons = []ttyButton{{"Yes", Yes}, {"No", No}, {"Cancel", Cancel}}
+        default:
+            d.buttons = []ttyButton{{"OK", Yes}, {"Cancel", Cancel}}
+    }
+
+    for i, b := range d.buttons {
+        if b.answer == req.DefaultButton { d.focus = i }
+    }
+
+    switch req.Kind {
+        case KindAlert, KindConfirm, KindQuestion:
+            if len(req.Details) == 0 { break }
+            d.details = strings.ReplaceAll(strings.TrimRight(req.Details, "\n"), "\t", "    ")
+            d.buttons = append(d.buttons, ttyButton{"Details", ttyDetails})
+    }
+
+    return d
+}
+
+// press presses the button with the given index.
+func (d *ttyDialog) press(button int) {
+    if d.buttons[button].answer == ttyDetails {
+        d.expanded = !d.expanded
+        return
+    }
+
+    d.done = true
+    d.answer = d.buttons[button].answer
+}
+
+// toggle checks or unchecks the current item, if any number of items can be
+// checked.
+func (d *ttyDialog) toggle() {
+    if d.many && (len(d.items) > 0) { d.checked[d.cursor] = !d.checked[d.cursor] }
+}
+
+// handle updates the dialog in response to an event, setting done when the
+// dialog is finished.
+func (d *ttyDialog) handle(e ttyEvent) {
+    var n = len(d.buttons)
+
+    switch e.key {
+        case ttyKeyEnter:
+            d.press(d.focus)
+        case ttyKeyEscape, ttyKeyInterrupt:
+            d.done, d.answer = true, 0
+        case ttyKeyRight, ttyKeyTab:
+            d.focus = (d.focus + 1) % n
+        case ttyKeyLeft, ttyKeyBacktab:
+            d.focus = (d.focus + n - 1) % n
+        case ttyKeyUp:
+            if d.cursor > 0 { d.cursor-- }
+        case ttyKeyDown:
+            if d.cursor < len(d.items) - 1 { d.cursor++ }
+
+        case ttyKeyRune:
+            if (e.r == ' ') && d.many {
+                d.toggle()
+                return
+            } else if e.r == ' ' {
+                d.press(d.focus)
+                return
+            }
+
+            // the first letter of a button presses it
+            for i, b := range d.buttons {
+                if strings.EqualFold(b.label[0:1], string(e.r)) {
+                    d.press(i)
+                    return
+                }
+            }
+
+        case ttyKeyClick:
+            for _, hit := range d.hits {
+                if (e.y != hit.y) || (e.x < hit.x0) || (e.x >= hit.x1) { continue }
+                if hit.button >= 0 {
+                    d.focus = hit.button
+                    d.press(hit.button)
+                } else {
+                    d.cursor = hit.item
+                    d.toggle()
+                }
+                return
+            }
+    }
+}
+
+// response returns the result of a finished dialog of the given kind.
+func (d *ttyDialog) response(kind Kind) (Response, error) {
+    if kind == KindAlert { return Response{}, nil }
+    if (d.answer == 0) || ((d.answer == Cancel) && (kind != KindQuestion)) {
+        return Response{}, ErrCancelled
+    }
+
+    switch kind {
+        case KindChoose:
+            if len(d.items) == 0 { return Response{}, ErrCancelled }
+            return Response{Choices: []int{d.cursor}}, nil
+        case KindChooseMany:
+            var choices = make([]int, 0)
+            for i, checked := range d.checked {
+                if checked { choices = append(choices, i) }
+            }
+            return Response{Choices: choices}, nil
+        default:
+            return Response{Answer: d.answer}, nil
+    }
+}
+
+// ttyPad truncates or pads s with spaces to exactly width columns.
+func ttyPad(s string, width int) string {
+    var length = textWidth(s)
+    if length > width {
+        if width < 1 { return "" }
+
+        var head, _ = splitWidth(s, width - 1)
+        if textWidth(head) > width - 1 { head = "" } // a wide first character
+        return head + "…" + strings.Repeat(" ", width - 1 - textWidth(head))
+    }
+    return s + strings.Repeat(" ", width - length)
+}
+
+// render returns the escape sequences that clear a terminal of the given size
+// and draw the dialog in a box in the middle of it, and records where each
+// button and item is drawn.
+func (d *ttyDialog) render(cols int, rows int) string {
+    var width = cols - 4 // inside the box, excluding borders and margins
+    if width > 64 { width = 64 }
+    if width < 10 { width = 10 }
+
+    var labels = make([]string, len(d.buttons))
+    var total = -2 // width of all buttons, two spaces apart
+    for i, button := range d.buttons {
+        labels[i] = "< " + button.label + " >"
+        total += textWidth(labels[i]) + 2
+    }
+    if width < total { width = total }
+
+    var lines = wrapLines(d.message, width)
+    if d.expanded {
+        // as many lines of details as will fit
+        var details = strings.Split(d.details, "\n")
+        var space = rows - len(lines) - 7
+        if len(details) > space {
+            if space < 1 { space = 1 }
+            details = append(details[0:space-1], "…")
+        }
+        lines = append(append(lines, ""), details...)
+    }
+
+    // as many items as will fit, scrolled so that the cursor is visible
+    var visible = len(d.items)
+    if space := rows - len(lines) - 7; visible > space { visible = space }
+    if visible < 3 { visible = 3 }
+    if visible > len(d.items) { visible = len(d.items) }
+    if d.cursor < d.offset { d.offset = d.cursor }
+    if d.cursor >= d.offset + visible { d.offset = d.cursor - visible + 1 }
+
+    var height = len(lines) + 5
+    if visible > 0 { height += visible + 1 }
+
+    var left = (cols - (width + 4)) / 2 + 1
+    var top = (rows - height) / 2 + 1
+    if left < 1 { left = 1 }
+    if top < 1 { top = 1 }
+
+    var b strings.Builder
+    var y = top
+    var line = func(text string) {
+        fmt.Fprintf(&b, "\x1b[%d;%dH│ %s │", y, left, text)
+        y++
+    }
+
+    d.hits = d.hits[:0]
+    b.WriteString("\x1b[0m\x1b[2J")
+
+    var title = " " + d.title + " "
+    if textWidth(title) > width { title = ttyPad(title, width) }
+    fmt.Fprintf(&b, "\x1b[%d;%dH┌─%s%s─┐", y, left, title,
+        strings.Repeat("─", width - textWidth(title)))
+    y++
+
+    line(strings.Repeat(" ", width))
+    for _, l := range lines { line(ttyPad(l, width)) }
+    line(strings.Repeat(" ", width))
+
+    for i := d.offset; i < d.offset + visible; i++ {
+        var marker, box = "  ", ""
+        if i == d.cursor { marker = "> " }
+        if d.many {
+            box = "[ ] "
+            if d.checked[i] { box = "[x] " }
+        }
+
+        var text = ttyPad(marker + box + d.items[i], width)
+        if i == d.cursor { text = "\x1b[1m" + text + "\x1b[0m" }
+        d.hits = append(d.hits, ttyHit{y: y, x0: left + 2, x1: left + 2 + width, button: -1, item: i})
+        line(text)
+    }
+    if visible > 0 { line(strings.Repeat(" ", width)) }
+
+    // buttons, centred
+    var x = left + 2 + (width - total) / 2
+    var row strings.Builder
+    row.WriteString(strings.Repeat(" ", (width - total) / 2))
+    for i, label := range labels {
+        var length = textWidth(label)
+        d.hits = append(d.hits, ttyHit{y: y, x0: x, x1: x + length, button: i, item: -1})
+        x += length + 2
+
+        if i == d.focus { label = "\x1b[7m" + label + "\x1b[0m" }
+        row.WriteString(label)
+        if i < len(labels) - 1 { row.WriteString("  ") }
+    }
+    row.WriteString(strings.Repeat(" ", width - total - (width - total) / 2))
+    line(row.String())
+
+    fmt.Fprintf(&b, "\x1b[%d;%dH└%s┘", y, left, strings.Repeat("─", width + 2))
+    return b.String()
+}
@@ -0,0 +1,170 @@
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "bufio"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestReadTTYEvent(t *testing.T) {
+    var tests = []struct{
+        input string
+        expected ttyEvent
+    }{
+        {"y",              ttyEvent{key: ttyKeyRune, r: 'y'}},
+        {"\r",             ttyEvent{key: ttyKeyEnter}},
+        {"\t",             ttyEvent{key: ttyKeyTab}},
+        {"\x03",           ttyEvent{key: ttyKeyInterrupt}},
+        {"\x1b",           ttyEvent{key: ttyKeyEscape}},
+        {"\x1b[A",         ttyEvent{key: ttyKeyUp}},
+        {"\x1bOB",         ttyEvent{key: ttyKeyDown}},
+        {"\x1b[C",         ttyEvent{key: ttyKeyRight}},
+        {"\x1b[1;5D",      ttyEvent{key: ttyKeyLeft}},
+        {"\x1b[Z",         ttyEvent{key: ttyKeyBacktab}},
+        {"\x1b[<0;12;7M",  ttyEvent{key: ttyKeyClick, x: 12, y: 7}},
+        {"\x1b[<0;12;7m",  ttyEvent{key: ttyKeyNone}}, // release
+        {"\x1b[<2;12;7M",  ttyEvent{key: ttyKeyNone}}, // right button
+        {"\x1b[<32;12;7M", ttyEvent{key: ttyKeyNone}}, // drag
+        {"\x1b[15~",       ttyEvent{key: ttyKeyNone}}, // F5
+    }
+
+    for index, test := range tests {
+        var in = bufio.NewReader(strings.NewReader(test.input))
+        var event, err = readTTYEvent(in)
+        if err != nil {
+            t.Errorf("Test %d: unexpected error: %v", index, err)
+        } else if event != test.expected {
+            t.Errorf("Test %d: got %+v but wanted %+v", index, event, test.expected)
+        }
+    }
+}
+
+func TestTTYDialog(t *testing.T) {
+    var key = func(k ttyKey) ttyEvent { return ttyEvent{key: k} }
+    var char = func(r rune) ttyEvent { return ttyEvent{key: ttyKeyRune, r: r} }
+
+    var tests = []struct{
+        kind Kind
+        defaultButton Answer
+        events []ttyEvent
+        expected Response
+        cancelled bool
+    }{
+        {KindAlert,      0,  []ttyEvent{key(ttyKeyEnter)},                     Response{}, false},
+        {KindAlert,      0,  []ttyEvent{key(ttyKeyEscape)},                    Response{}, false},
+        {KindConfirm,    0,  []ttyEvent{key(ttyKeyEnter)},                     Response{Answer: Yes}, false},
+        {KindConfirm,    No, []ttyEvent{key(ttyKeyEnter)},                     Response{Answer: No}, false},
+        {KindConfirm,    0,  []ttyEvent{key(ttyKeyRight), char(' ')},          Response{Answer: No}, false},
+        {KindConfirm,    0,  []ttyEvent{char('x'), char('N')},                 Response{Answer: No}, false},
+        {KindConfirm,    0,  []ttyEvent{key(ttyKeyInterrupt)},                 Response{}, true},
+        {KindQuestion,   0,  []ttyEvent{key(ttyKeyBacktab), key(ttyKeyEnter)}, Response{Answer: Cancel}, false},
+        {KindQuestion,   0,  []ttyEvent{char('c')},                            Response{Answer: Cancel}, false},
+        {KindChoose,     0,  []ttyEvent{key(ttyKeyDown), key(ttyKeyDown), key(ttyKeyUp), key(ttyKeyEnter)},
+            Response{Choices: []int{1}}, false},
+        {KindChoose,     0,  []ttyEvent{key(ttyKeyDown), char('c')},           Response{}, true},
+        {KindChooseMany, 0,  []ttyEvent{char(' '), key(ttyKeyDown), key(ttyKeyDown), char(' '), key(ttyKeyEnter)},
+            Response{Choices: []int{0, 2}}, false},
+        {KindChooseMany, 0,  []ttyEvent{key(ttyKeyEnter)},                     Response{Choices: []int{}}, false},
+    }
+
+    for index, test := range tests {
+        var d = newTTYDialog(&Request{
+            Kind: test.kind,
+            Message: "Message",
+            Options: Options{Title: "Title", DefaultButton: test.defaultButton},
+            Items: []string{"apple", "banana", "cherry"},
+        })
+
+        for _, e := range test.events {
+            if d.done { t.Errorf("Test %d: finished early", index); break }
+            d.render(80, 24)
+            d.handle(e)
+        }
+        if !d.done {
+            t.Errorf("Test %d: not finished", index)
+            continue
+        }
+
+        var response, err = d.response(test.kind)
+        if (err == ErrCancelled) != test.cancelled {
+            t.Errorf("Test %d: unexpected error status: %v", index, err)
+        } else if !reflect.DeepEqual(response, test.expected) {
+            t.Errorf("Test %d: got %+v but wanted %+v", index, response, test.expected)
+        }
+    }
+}
+
+func TestTTYDialogDetails(t *testing.T) {
+    var d = newTTYDialog(&Request{
+        Kind: KindConfirm,
+        Message: "Something went wrong",
+        Options: Options{Title: "Title", Details: "line one\nline two"},
+    })
+
+    if strings.Contains(d.render(80, 24), "line two") {
+        t.Errorf("expected details to be hidden")
+    }
+
+    d.handle(ttyEvent{key: ttyKeyRune, r: 'd'})
+    if d.done || !strings.Contains(d.render(80, 24), "line two") {
+        t.Errorf("expected details to be shown")
+    }
+
+    d.handle(ttyEvent{key: ttyKeyRune, r: 'y'})
+    if response, err := d.response(KindConfirm); (err != nil) || (response.Answer != Yes) {
+        t.Errorf("got (%+v, %v)", response, err)
+    }
+}
+
+func TestTTYDialogClick(t *testing.T) {
+    var d = newTTYDialog(&Request{
+        Kind: KindChooseMany,
+        Options: Options{Title: "Title"},
+        Items: []string{"apple", "banana", "cherry"},
+    })
+    d.render(80, 24)
+
+    var click = func(button int, item int) {
+        for _, hit := range d.hits {
+            if (hit.button == button) && (hit.item == item) {
+                d.handle(ttyEvent{key: ttyKeyClick, x: hit.x0, y: hit.y})
+                return
+            }
+        }
+        t.Fatalf("nothing to click for button %d, item %d", button, item)
+    }
+
+    click(-1, 2)
+    click(-1, 1)
+    click(-1, 2)
+    click(0, -1)
+
+    var response, err = d.response(KindChooseMany)
+    if (err != nil) || !reflect.DeepEqual(response.Choices, []int{1}) {
+        t.Errorf("got (%+v, %v)", response, err)
+    }
+}
+
+func TestTTYPad(t *testing.T) {
+    var tests = []struct{
+        input string
+        width int
+        expected string
+    }{
+        {"abc",    5, "abc  "},
+        {"abcdef", 5, "abcd…"},
+        {"日本語",  7, "日本語 "},
+        {"日本語",  5, "日本…"},
+        {"日本語",  4, "日… "},
+        {"日本語",  2, "… "},
+        {"ééé", 2, "é…"},
+        {"abc",    0, ""},
+    }
+
+    for index, test := range tests {
+        if result := ttyPad(test.input, test.width); result != test.expected {
+            t.Errorf("Test %d: got %q but wanted %q", index, result, test.expected)
+        }
+    }
+}
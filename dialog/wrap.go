@@ -2,38 +2,120 @@ package dialog // import "tawesoft.co.uk/go/dialog"
 
 import (
     "strings"
+    "unicode"
+    "unicode/utf8"
+
+    "golang.org/x/text/width"
 )
 
-// quickly wraps a message for error output
+// runeWidth returns the number of columns that a rune occupies in a terminal
+// or a fixed-width font: 2 for wide East Asian characters (including most
+// emoji), 0 for combining marks and other invisible characters, otherwise 1.
+func runeWidth(r rune) int {
+    if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Cc) { return 0 }
+
+    switch width.LookupRune(r).Kind() {
+        case width.EastAsianWide, width.EastAsianFullwidth: return 2
+        default:                                           return 1
+    }
+}
+
+// textWidth returns the number of columns that a string occupies in a
+// terminal or a fixed-width font.
+func textWidth(s string) int {
+    var n int
+    for _, r := range s { n += runeWidth(r) }
+    return n
+}
+
+// splitWidth splits s into a prefix at most n columns wide and the rest. The
+// prefix is never empty, even if its first character is wider than n, and
+// zero-width characters such as combining marks stay with the character
+// before them.
+func splitWidth(s string, n int) (string, string) {
+    var total, end int
+    for index, r := range s {
+        var w = runeWidth(r)
+        if (total + w > n) && (end > 0) { return s[0:index], s[index:] }
+        total += w
+        end = index + utf8.RuneLen(r)
+    }
+    return s, ""
+}
+
+// wrap wraps a message to lines at most length columns wide, measured with
+// textWidth, for display in a terminal or a fixed-width font.
+//
+// Line breaks are kept, and paragraphs, separated by one or more blank lines,
+// are kept apart by exactly one blank line. Within a line, whitespace is
+// collapsed and the words are reflowed. A word wider than a whole line is put
+// on a line of its own: use wrapLines to break it too.
 func wrap(message string, length int) string {
-    var atoms = strings.Fields(strings.TrimSpace(message))
-    var results = make([]string, 0, 16)
-    var currentLength int
-
-    for _, atom := range atoms {
-        // special case for an atom longer than a whole line
-        if (currentLength == 0) && (len(atom) >= length) {
-            results = append(results, atom)
-            results = append(results, "\n")
-            currentLength = 0
-            continue
-        }
+    if length < 1 { length = 1 }
 
-        // will overflow?
-        if currentLength + len(atom) + 1 > length {
-            results = append(results, "\n")
-            currentLength = 0
-        }
+    var paragraphs = make([]string, 0, 4)
+    var lines []string
+    var end = func() {
+        if len(lines) == 0 { return }
+        paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+        lines = nil
+    }
 
-        // mid-line?
-        if currentLength > 0 {
-            results = append(results, " ")
-            currentLength += 1
+    for _, line := range strings.Split(message, "\n") {
+        var fields = strings.Fields(line)
+        if len(fields) == 0 { end(); continue }
+        lines = append(lines, wrapWords(fields, length))
+    }
+    end()
+
+    return strings.Join(paragraphs, "\n\n")
+}
+
+// wrapWords joins words with a space or a newline so that each line is at
+// most length columns wide, except for a word that is too wide for any line.
+func wrapWords(words []string, length int) string {
+    var b strings.Builder
+    var current int // width of the current line
+
+    for _, word := range words {
+        var w = textWidth(word)
+
+        if current > 0 {
+            if current + 1 + w <= length {
+                b.WriteString(" ")
+                current++
+            } else {
+                b.WriteString("\n")
+                current = 0
+            }
         }
 
-        results = append(results, atom)
-        currentLength += len(atom)
+        b.WriteString(word)
+        current += w
     }
 
-    return strings.TrimSpace(strings.Join(results, ""))
+    return b.String()
+}
+
+// wrapLines is like wrap, but returns each line separately, and breaks a word
+// wider than a whole line, such as a long path or URL, across several lines.
+func wrapLines(message string, length int) []string {
+    if length < 1 { length = 1 }
+    return splitLines(wrap(message, length), length)
+}
+
+// splitLines splits text into lines, breaking any line wider than length
+// columns, such as a long word, across several lines.
+func splitLines(text string, length int) []string {
+    var lines = make([]string, 0, 8)
+    for _, line := range strings.Split(text, "\n") {
+        for textWidth(line) > length {
+            var head, rest = splitWidth(line, length)
+            if len(rest) == 0 { break }
+            lines = append(lines, head)
+            line = rest
+        }
+        lines = append(lines, line)
+    }
+    return lines
 }
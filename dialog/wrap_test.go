@@ -1,6 +1,7 @@
 package dialog // import "tawesoft.co.uk/go/dialog"
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -18,7 +19,7 @@ func TestWraps(t *testing.T) {
         {"hello world", "hello\nworld", 10},
         {"hello world", "hello world",  11},
         {"hello world", "hello world",  12},
-        {"hello\nworld", "hello world",  12},
+        {"hello\nworld", "hello\nworld",  12},
         {"hello      world", "hello world", 12},
         {"    hello    world   ", "hello world", 12},
         {"a b c d e f g h i", "a\nb\nc\nd\ne\nf\ng\nh\ni", -1},
@@ -28,17 +29,28 @@ func TestWraps(t *testing.T) {
         {"a b c d e f g h i", "a b c\nd e f\ng h i", 6},
         {"a b c d e f g h i", "a b c d e\nf g h i",  9},
         {"a b c d e f g h i", "a b c d e\nf g h i", 10},
-        {`Lorem ipsum dolor sit amet, consectetur adipiscing elit. Fusce a
-        tortor sagittis, elementum velit id, scelerisque erat. Sed mollis odio
-        molestie dui venenatis condimentum. Donec massa ligula, auctor rutrum
-        interdum a, faucibus sed sapien. Vivamus neque massa, porttitor vel
-        nulla eu, gravida egestas massa. Aliquam interdum pellentesque elit.
-        Quisque vestibulum, libero condimentum venenatis commodo, erat lectus
-        convallis libero, at pellentesque nibh enim vel risus. Duis elit mi,
-        lacinia ut ex vitae, ullamcorper tempus ex. Lorem ipsum dolor sit amet,
-        consectetur adipiscing elit. Fusce eu elit molestie, tempor nulla
-        vehicula, tempor nulla. Maecenas pellentesque, lectus non accumsan
-        pharetra, neque justo dignissim dolor, sit amet luctus mi leo ut dui.`,
+        {"abcdefgh ij", "abcdefgh\nij", 3},
+        {"hello\n\nworld", "hello\n\nworld", 80},
+        {"\n\na\nb\n  \n\n c  d \n\n", "a\nb\n\nc d", 80},
+        {"one two\n\nthree four", "one\ntwo\n\nthree\nfour", 5},
+        {"a b c\nd e f", "a b\nc\nd e\nf", 3},
+        {"日本語 テキスト", "日本語\nテキスト", 10},
+        {"日本 語", "日本 語", 7},
+        {"日本 語", "日本\n語", 6},
+        {"😀😀 😀", "😀😀\n😀", 5},
+        {"cafe\u0301 cafe\u0301", "cafe\u0301 cafe\u0301", 9},
+        {"cafe\u0301 cafe\u0301", "cafe\u0301\ncafe\u0301", 8},
+        {"Lorem ipsum dolor sit amet, consectetur adipiscing elit. Fusce a " +
+         "tortor sagittis, elementum velit id, scelerisque erat. Sed mollis odio " +
+         "molestie dui venenatis condimentum. Donec massa ligula, auctor rutrum " +
+         "interdum a, faucibus sed sapien. Vivamus neque massa, porttitor vel " +
+         "nulla eu, gravida egestas massa. Aliquam interdum pellentesque elit. " +
+         "Quisque vestibulum, libero condimentum venenatis commodo, erat lectus " +
+         "convallis libero, at pellentesque nibh enim vel risus. Duis elit mi, " +
+         "lacinia ut ex vitae, ullamcorper tempus ex. Lorem ipsum dolor sit amet, " +
+         "consectetur adipiscing elit. Fusce eu elit molestie, tempor nulla " +
+         "vehicula, tempor nulla. Maecenas pellentesque, lectus non accumsan " +
+         "pharetra, neque justo dignissim dolor, sit amet luctus mi leo ut dui.",
 
         `Lorem ipsum dolor sit amet,
 consectetur adipiscing elit.
@@ -80,3 +92,45 @@ amet luctus mi leo ut dui.`,
         }
     }
 }
+
+func TestSplitLines(t *testing.T) {
+    var tests = []struct{
+        input string
+        length int
+        expected []string
+    }{
+        {"",             3, []string{""}},
+        {"abc\nd",       3, []string{"abc", "d"}},
+        {"abcdefgh\nij", 3, []string{"abc", "def", "gh", "ij"}},
+        {"日本語",       4, []string{"日本", "語"}},
+        {"日本",         1, []string{"日", "本"}},
+        {"cafe\u0301cafe\u0301", 4, []string{"cafe\u0301", "cafe\u0301"}},
+    }
+
+    for index, test := range tests {
+        if result := splitLines(test.input, test.length); !reflect.DeepEqual(result, test.expected) {
+            t.Errorf("Test %d: got %q but wanted %q", index, result, test.expected)
+        }
+    }
+}
+
+func TestWrapLines(t *testing.T) {
+    var tests = []struct{
+        input string
+        length int
+        expected []string
+    }{
+        {"",                       3, []string{""}},
+        {"hello world",            5, []string{"hello", "world"}},
+        {"see /a/very/long/path.", 8, []string{"see", "/a/very/", "long/pat", "h."}},
+        {"abcdefgh ij",            3, []string{"abc", "def", "gh", "ij"}},
+        {"日本語 テキスト",         4, []string{"日本", "語", "テキ", "スト"}},
+        {"a\n\nbcd",               0, []string{"a", "", "b", "c", "d"}},
+    }
+
+    for index, test := range tests {
+        if result := wrapLines(test.input, test.length); !reflect.DeepEqual(result, test.expected) {
+            t.Errorf("Test %d: got %q but wanted %q", index, result, test.expected)
+        }
+    }
+}
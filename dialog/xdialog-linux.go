@@ -0,0 +1,137 @@
+// +build linux
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "os/exec"
+    "strconv"
+    "strings"
+)
+
+// xdialogProvider uses Xdialog, an X11 version of the dialog(1) command,
+// which writes results to stderr.
+//
+// Xdialog can't ask a three-way question: its message boxes have at most two
+// buttons, and the only way to add a third, --wizard, adds a "Previous"
+// button that can't be relabelled. Its file selector, from GTK 1, can only
+// select one file at a time. So KindQuestion and KindOpenFiles are
+// unsupported, and are left to the next provider.
+type xdialogProvider struct {
+    command string
+}
+
+// exit status of Xdialog when a dialog is closed or escape is pressed
+const xdialogClosed = 255
+
+func (p xdialogProvider) Name() string { return "Xdialog" }
+
+func (p xdialogProvider) Show(ctx context.Context, req *Request) (Response, error) {
+    // Xdialog takes a height and width after the text, where 0 means "auto"
+    var args = []string{"--title", req.Title, "--wrap"}
+
+    switch req.Kind {
+        case KindAlert:
+            args = append(args, "--msgbox", req.PlainMessage(), "0", "0")
+            var err = exec.CommandContext(ctx, p.command, args...).Run()
+            switch code := exitCode(err); code {
+                case 0, xdialogClosed: return Response{}, nil // "OK", or the dialog was closed
+                case -1:               return Response{}, err
+                default:               return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+            }
+
+        case KindConfirm:
+            if req.DefaultButton == No { args = append(args, "--default-no") }
+            args = append(args, "--yesno", req.PlainMessage(), "0", "0")
+            var err = exec.CommandContext(ctx, p.command, args...).Run()
+
+            switch code := exitCode(err); code {
+                case 0:                return Response{Answer: Yes}, nil
+                case 1, xdialogClosed: return Response{Answer: No}, nil
+                case -1:               return Response{}, err
+                default:               return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+            }
+
+        case KindPrompt, KindPassword:
+            if req.Kind == KindPassword { args = append(args, "--password") }
+            args = append(args, "--inputbox", req.Message, "0", "0", req.Default)
+            return p.output(ctx, args, func(out string) (Response, error) {
+                return Response{Text: out}, nil
+            })
+
+        case KindOpenFile, KindSaveFile:
+            args = append(args, "--fselect", startPath(req), "0", "0")
+            return p.output(ctx, args, func(out string) (Response, error) {
+                return Response{Paths: []string{out}}, nil
+            })
+
+        case KindSelectFolder:
+            args = append(args, "--dselect", startPath(req), "0", "0")
+            return p.output(ctx, args, func(out string) (Response, error) {
+                return Response{Paths: []string{out}}, nil
+            })
+
+        case KindChoose, KindChooseMany:
+            // each item is tagged with its number, counting from 1, which is
+            // printed for each selected item
+            var rows = len(req.Items)
+            if rows > 10 { rows = 10 }
+
+            args = append(args, "--no-tags")
+            if req.Kind == KindChooseMany {
+                args = append(args, "--separate-output", "--checklist")
+            } else {
+                args = append(args, "--menubox")
+            }
+            args = append(args, req.PlainMessage(), "0", "0", strconv.Itoa(rows))
+
+            for i, item := range req.Items {
+                args = append(args, strconv.Itoa(i + 1), item)
+                if req.Kind == KindChooseMany { args = append(args, "off") }
+            }
+
+            return p.output(ctx, args, func(out string) (Response, error) {
+                return choiceResponse(p.command, out, len(req.Items))
+            })
+
+        case KindPickDate:
+            // the calendar prints a date in the form dd/mm/yyyy
+            args = append(args, "--calendar", req.PlainMessage(), "0", "0",
+                strconv.Itoa(req.Date.Day()), strconv.Itoa(int(req.Date.Month())), strconv.Itoa(req.Date.Year()))
+            return p.output(ctx, args, func(out string) (Response, error) {
+                return pickResponse(p.command, req, isoDate(out, "02/01/2006"))
+            })
+
+        case KindPickNumber:
+            args = append(args, "--rangebox", req.PlainMessage(), "0", "0",
+                strconv.Itoa(req.Min), strconv.Itoa(req.Max), strconv.Itoa(req.Number))
+            return p.output(ctx, args, func(out string) (Response, error) {
+                return pickResponse(p.command, req, out)
+            })
+
+        default:
+            // including KindQuestion and KindOpenFiles (see xdialogProvider)
+            return Response{}, ErrUnsupported
+    }
+}
+
+// output runs an Xdialog dialog that returns its result on stderr.
+func (p xdialogProvider) output(
+    ctx context.Context,
+    args []string,
+    f func(out string) (Response, error),
+) (Response, error) {
+    var stderr bytes.Buffer
+    var cmd = exec.CommandContext(ctx, p.command, args...)
+    cmd.Stderr = &stderr
+
+    var err = cmd.Run()
+    switch code := exitCode(err); code {
+        case 0:                return f(strings.TrimSuffix(stderr.String(), "\n"))
+        case 1, xdialogClosed: return Response{}, ErrCancelled
+        case -1:               return Response{}, err
+        default:               return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+    }
+}
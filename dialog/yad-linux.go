@@ -0,0 +1,169 @@
+// +build linux
+
+package dialog // import "tawesoft.co.uk/go/dialog"
+
+import (
+    "context"
+    "fmt"
+    "os/exec"
+    "strconv"
+    "strings"
+)
+
+// yadProvider uses yad ("yet another dialog"), a fork of zenity with
+// different options. yad always interprets text as Pango markup.
+type yadProvider struct {
+    command string
+}
+
+// exit status of yad when a dialog is closed or escape is pressed
+const yadClosed = 252
+
+func (p yadProvider) Name() string { return "yad" }
+
+func (p yadProvider) Show(ctx context.Context, req *Request) (Response, error) {
+    var args = []string{"--title", req.Title, "--center"}
+    if req.Width > 0 {
+        args = append(args, fmt.Sprintf("--width=%d", req.Width))
+    } else if req.Kind.isFile() {
+        args = append(args, "--width=600")
+    } else {
+        args = append(args, "--width=400")
+    }
+    if req.Height > 0 {
+        args = append(args, fmt.Sprintf("--height=%d", req.Height))
+    } else if req.Kind.isFile() {
+        args = append(args, "--height=400")
+    }
+    var text = "--text="+escapeMarkup(req.PlainMessage())
+
+    switch req.Kind {
+        case KindAlert:
+            var icon = "dialog-information"
+            switch req.Level {
+                case LevelWarning: icon = "dialog-warning"
+                case LevelError:   icon = "dialog-error"
+            }
+
+            args = append(args, text, "--image="+icon, "--window-icon="+icon, "--button=OK:0")
+            var err = exec.CommandContext(ctx, p.command, args...).Run()
+            switch code := exitCode(err); code {
+                case 0, 1, yadClosed: return Response{}, nil // "OK", or the dialog was closed
+                case -1:              return Response{}, err
+                default:              return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+            }
+
+        case KindConfirm, KindQuestion:
+            args = append(args, text, "--image=dialog-question", "--button=Yes:0", "--button=No:1")
+            if req.Kind == KindQuestion { args = append(args, "--button=Cancel:2") }
+
+            var err = exec.CommandContext(ctx, p.command, args...).Run()
+            switch code := exitCode(err); code {
+                case 0: return Response{Answer: Yes}, nil
+                case 1: return Response{Answer: No}, nil
+                case 2, yadClosed:
+                    if req.Kind == KindQuestion { return Response{Answer: Cancel}, nil }
+                    return Response{Answer: No}, nil
+                case -1: return Response{}, err
+                default: return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+            }
+
+        case KindPrompt, KindPassword:
+            args = append(args, text, "--entry", "--entry-text="+req.Default)
+            if req.Kind == KindPassword { args = append(args, "--hide-text") }
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            return p.result(err, func() Response {
+                return Response{Text: strings.TrimSuffix(string(out), "\n")}
+            })
+
+        case KindOpenFile, KindOpenFiles, KindSaveFile, KindSelectFolder:
+            args = append(args, "--file")
+
+            switch req.Kind {
+                case KindOpenFiles:    args = append(args, "--multiple", "--separator=\n")
+                case KindSaveFile:     args = append(args, "--save", "--confirm-overwrite")
+                case KindSelectFolder: args = append(args, "--directory")
+            }
+
+            if path := startPath(req); len(path) > 0 {
+                args = append(args, "--filename="+path)
+            }
+
+            for _, f := range req.Filters {
+                args = append(args, "--file-filter="+f.Name+" | "+strings.Join(f.Patterns, " "))
+            }
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            return p.result(err, func() Response {
+                return Response{Paths: strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")}
+            })
+
+        case KindChoose, KindChooseMany:
+            // each row is a hidden item number (counting from 1), which is
+            // printed on its own line for each selected row, followed by the
+            // item itself
+            args = append(args, text, "--list", "--no-headers", "--separator=\n")
+            if req.Height == 0 { args = append(args, "--height=300") }
+
+            if req.Kind == KindChooseMany {
+                args = append(args, "--checklist", "--column=", "--column=#", "--column=Item",
+                    "--hide-column=2", "--print-column=2")
+            } else {
+                args = append(args, "--column=#", "--column=Item",
+                    "--hide-column=1", "--print-column=1")
+            }
+
+            for i, item := range req.Items {
+                if req.Kind == KindChooseMany { args = append(args, "FALSE") }
+                args = append(args, strconv.Itoa(i + 1), item)
+            }
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            if err != nil { return p.result(err, nil) }
+            return choiceResponse(p.command, string(out), len(req.Items))
+
+        case KindPickDate, KindPickColor, KindPickNumber:
+            switch req.Kind {
+                case KindPickDate:
+                    args = append(args, text, "--calendar", "--date-format=%Y-%m-%d",
+                        fmt.Sprintf("--day=%d", req.Date.Day()),
+                        fmt.Sprintf("--month=%d", req.Date.Month()),
+                        fmt.Sprintf("--year=%d", req.Date.Year()))
+                case KindPickColor:
+                    args = append(args, "--color", "--init-color="+formatColor(req.Color))
+                case KindPickNumber:
+                    args = append(args, text, "--scale",
+                        fmt.Sprintf("--min-value=%d", req.Min),
+                        fmt.Sprintf("--max-value=%d", req.Max),
+                        fmt.Sprintf("--step=%d", req.Step),
+                        fmt.Sprintf("--value=%d", req.Number))
+            }
+
+            var out, err = exec.CommandContext(ctx, p.command, args...).Output()
+            if err != nil { return p.result(err, nil) }
+            return pickResponse(p.command, req, string(out))
+
+        default:
+            return Response{}, ErrUnsupported
+    }
+}
+
+// result interprets the exit status of a yad dialog that returns its result
+// on stdout.
+func (p yadProvider) result(err error, f func() Response) (Response, error) {
+    switch code := exitCode(err); code {
+        case 0:            return f(), nil
+        case 1, yadClosed: return Response{}, ErrCancelled
+        case -1:           return Response{}, err
+        default:           return Response{}, fmt.Errorf("%s: unexpected exit status %d", p.command, code)
+    }
+}
+
+// escapeMarkup escapes text so that it is displayed as-is by a program that
+// interprets Pango markup.
+var escapeMarkup = strings.NewReplacer(
+    "&", "&amp;",
+    "<", "&lt;",
+    ">", "&gt;",
+).Replace
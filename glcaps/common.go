@@ -1,9 +1,35 @@
 package glcaps
 
 import (
+    "errors"
+    "fmt"
     "sort"
 )
 
+// Errors wrapped by an Error, for use with errors.Is, identifying the category of failure.
+var (
+    ErrSyntax          = errors.New("tag syntax error")
+    ErrKind            = errors.New("type mismatch")
+    ErrUnknownConstant = errors.New("unknown OpenGL constant")
+    ErrNoContext       = errors.New("no current OpenGL context")
+    ErrRequirement     = errors.New("requirement not met")
+    ErrMissing         = errors.New("not in profile")
+)
+
+// failure is an error that belongs to one of the categories above, but keeps its own message.
+type failure struct {
+    kind error
+    message string
+}
+
+func (f failure) Error() string { return f.message }
+func (f failure) Unwrap() error { return f.kind }
+
+// fail returns a failure of a given kind with a printf-style message.
+func fail(kind error, format string, args ... interface{}) error {
+    return failure{kind, fmt.Sprintf(format, args...)}
+}
+
 // Extensions is an ordered list of supported OpenGL extensions.
 type Extensions []string
 
@@ -20,15 +46,37 @@ type Binding struct {
     GetFloatv   func(name uint32, data *float32)
     GetString   func(name uint32) string // required to return a Go string, not a C string!
     GetStringi  func(name uint32, index uint32) string // required to return a Go string, not a C string!
+    
+    // Optional, for the GetIntegeri_v and GetFloati_v commands (OpenGL 3.0+ or OpenGL ES 3.0+)
+    GetIntegeri_v func(name uint32, index uint32, data *int32)
+    GetFloati_v   func(name uint32, index uint32, data *float32)
+    
+    // missing, if set, returns and forgets the name of every constant queried but not in a strict profile
+    missing func() []string
 }
 
-// QueryExtensions returns all extensions supported by the current OpenGL context as a sorted list of strings. It is an
-// error to call this method if a current OpenGL context does not exist.
+// QueryExtensions returns all extensions supported by the current OpenGL context as a sorted list of strings. It
+// panics if a current OpenGL context does not exist.
+//
+// Deprecated: use QueryExtensionsE, which returns an error instead of panicking.
 func (b *Binding) QueryExtensions() Extensions {
-    var numExtensions int32
+    var xs, err = b.QueryExtensionsE()
+    if err != nil { panic(err.Error()) }
+    return xs
+}
+
+// QueryExtensionsE is like QueryExtensions, but returns an error wrapping ErrNoContext if a current OpenGL context
+// does not exist. A context that supports no extensions at all returns an empty list.
+func (b *Binding) QueryExtensionsE() (Extensions, error) {
+    if (b == nil) || (b.GetIntegerv == nil) || (b.GetStringi == nil) {
+        return nil, fail(ErrNoContext, "binding has no GetIntegerv or GetStringi")
+    }
+    
+    // without a current context, OpenGL doesn't write a result at all
+    var numExtensions int32 = -1
     b.GetIntegerv(glconstants["GL_NUM_EXTENSIONS"], &numExtensions)
-    if numExtensions <= 0 {
-        panic("failed to query OpenGL extensions (is the OpenGL context current?)")
+    if numExtensions < 0 {
+        return nil, fail(ErrNoContext, "failed to query OpenGL extensions (is the OpenGL context current?)")
     }
 
     var xs = make([]string, 0, numExtensions)
@@ -40,15 +88,25 @@ func (b *Binding) QueryExtensions() Extensions {
     }
 
     sort.Strings(xs)
-    return xs
+    return xs, nil
 }
 
-// Error implements an error result type for reporting a capability that doesn't meet a requirement.
+// Error implements an error result type for reporting a capability that doesn't meet a requirement, or a field that
+// could not be parsed or evaluated at all.
 type Error struct {
     Field       string // the name of the field in the struct that failed
     Tag         string // the original tag string
-    Requirement requirement // the requirement that failed
+    Requirement requirement // the requirement that failed, if any
     Message     string // a human-readable message
+    Err         error // the underlying error, which wraps one of ErrSyntax, ErrKind, etc.
+}
+
+func (e Error) Error() string {
+    return e.Message
+}
+
+func (e Error) Unwrap() error {
+    return e.Err
 }
 
 type Errors []Error
@@ -60,7 +118,3 @@ func (es *Errors) append(e ... Error) {
     
     *es = append(*es, e...)
 }
-
-
-
-
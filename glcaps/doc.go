@@ -25,6 +25,11 @@
 // care about, including minimum required extensions or capabilities. Glcaps has
 // no dependencies and is agnostic to the exact OpenGL binding used.
 // 
+// Capabilities can also be checked without a GPU (for example, in CI) by
+// replaying a Profile recorded from a real OpenGL context, or one of the bundled
+// reference profiles such as "GL 3.3 core minimum" or "GLES 3.0 minimum". A
+// strict replay also reports anything queried that the profile doesn't have.
+// 
 // OpenGL® and the oval logo are trademarks or registered trademarks of Hewlett
 // Packard Enterprise in the United States and/or other countries worldwide.
 // 
@@ -12,7 +12,9 @@ package main
 //     Supports.TextureCompressionBPTC: true
 //     Supports.FluxCapacitor: false
 //     Supports.BigTextures: true
+//     Version: 4.6 core
 //     MaxTextureUnits: 192
+//     MaxViewportDims: [32768 32768]
 //     Frobbinators: 150
 //     380 extensions supported
 //
@@ -71,9 +73,11 @@ func main() {
             FluxCapacitor           bool `glcaps:"and ext FLUX1 ext FLUX2; required"`
         }
 
+        Version                     glcaps.Version `glcaps:"version GL_VERSION; gte 3.3 profile core"`
         MaxTextureUnits             int     `glcaps:"GetIntegerv GL_MAX_COMBINED_TEXTURE_IMAGE_UNITS"`
         MaxTextureSize              int     `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE; gte 8192"`
         MaxAnisotropy               float32 `glcaps:"if ext GL_EXT_texture_filter_anisotropic GetFloatv GL_MAX_TEXTURE_MAX_ANISOTROPY 1.0"`
+        MaxViewportDims             [2]int  `glcaps:"GetIntegerv GL_MAX_VIEWPORT_DIMS; gte 4096"`
         Frobbinators                int     `glcaps:"150; gte 10 lt 100 neq 13"`
     }
 
@@ -86,6 +90,7 @@ func main() {
         GetStringi:  func(name uint32, index uint32) string {
             return gl.GoStr(gl.GetStringi(name, index))
         },
+        GetIntegeri_v: gl.GetIntegeri_v,
     }
 
     var MyCaps Caps
@@ -98,7 +103,9 @@ func main() {
     fmt.Printf("Supports.TextureCompressionBPTC: %t\n", MyCaps.Supports.BPTextureCompression)
     fmt.Printf("Supports.FluxCapacitor: %t\n", MyCaps.Supports.FluxCapacitor)
     fmt.Printf("Supports.BigTextures: %t\n", MyCaps.Supports.BigTextures)
+    fmt.Printf("Version: %s\n", MyCaps.Version)
     fmt.Printf("MaxTextureUnits: %d\n", MyCaps.MaxTextureUnits)
+    fmt.Printf("MaxViewportDims: %v\n", MyCaps.MaxViewportDims)
     fmt.Printf("Frobbinators: %d\n", MyCaps.Frobbinators)
     fmt.Printf("%d extensions supported\n", len(extensions))
 
@@ -0,0 +1,76 @@
+package glcaps
+
+// maxResults is the most values written by any single fixed-size OpenGL query (a 4x4 matrix), used as a minimum
+// buffer size so that a query for the wrong constant can't write out of bounds.
+const maxResults = 16
+
+// glresults is the number of values returned by glGetIntegerv or glGetFloatv for constants that return more than
+// one. Anything not listed here returns one value.
+var glresults = map[string]int {
+    "GL_ALIASED_LINE_WIDTH_RANGE": 2,
+    "GL_ALIASED_POINT_SIZE_RANGE": 2,
+    "GL_BLEND_COLOR":              4,
+    "GL_COLOR_CLEAR_VALUE":        4,
+    "GL_COLOR_WRITEMASK":          4,
+    "GL_DEPTH_RANGE":              2,
+    "GL_MAX_VIEWPORT_DIMS":        2,
+    "GL_POINT_SIZE_RANGE":         2,
+    "GL_SCISSOR_BOX":              4,
+    "GL_SMOOTH_LINE_WIDTH_RANGE":  2,
+    "GL_SMOOTH_POINT_SIZE_RANGE":  2,
+    "GL_VIEWPORT":                 4,
+    "GL_VIEWPORT_BOUNDS_RANGE":    2,
+}
+
+// glindexes is the number of indexes accepted by glGetIntegeri_v or glGetFloati_v for constants that accept a fixed
+// number. Anything not listed here accepts one index.
+var glindexes = map[string]int {
+    "GL_MAX_COMPUTE_WORK_GROUP_COUNT": 3,
+    "GL_MAX_COMPUTE_WORK_GROUP_SIZE":  3,
+}
+
+// glcounts maps constants that return a variable number of values to the constant that returns that number.
+var glcounts = map[string]string {
+    "GL_COMPRESSED_TEXTURE_FORMATS": "GL_NUM_COMPRESSED_TEXTURE_FORMATS",
+    "GL_PROGRAM_BINARY_FORMATS":     "GL_NUM_PROGRAM_BINARY_FORMATS",
+    "GL_SHADER_BINARY_FORMATS":      "GL_NUM_SHADER_BINARY_FORMATS",
+}
+
+// glindexcounts maps constants that accept a variable number of indexes to the constant that returns that number.
+var glindexcounts = map[string]string {
+    "GL_DEPTH_RANGE":  "GL_MAX_VIEWPORTS",
+    "GL_SCISSOR_BOX":  "GL_MAX_VIEWPORTS",
+    "GL_SCISSOR_TEST": "GL_MAX_VIEWPORTS",
+    "GL_VIEWPORT":     "GL_MAX_VIEWPORTS",
+}
+
+// resultCount returns the number of values returned by glGetIntegerv or glGetFloatv for the named constant.
+func resultCount(b *Binding, name string) (int, error) {
+    if count, ok := glcounts[name]; ok { return queryCount(b, count) }
+    if n, ok := glresults[name]; ok { return n, nil }
+    return 1, nil
+}
+
+// indexCount returns the number of indexes accepted by glGetIntegeri_v or glGetFloati_v for the named constant.
+func indexCount(b *Binding, name string) (int, error) {
+    if count, ok := glindexcounts[name]; ok { return queryCount(b, count) }
+    if n, ok := glindexes[name]; ok { return n, nil }
+    return 1, nil
+}
+
+// queryCount returns the result of glGetIntegerv for a constant that counts something.
+func queryCount(b *Binding, name string) (int, error) {
+    if b.GetIntegerv == nil { return 0, fail(ErrNoContext, "binding has no GetIntegerv") }
+    var result = make([]int32, maxResults)
+    b.GetIntegerv(glconstants[name], &result[0])
+    if result[0] < 0 { return 0, nil }
+    return int(result[0]), nil
+}
+
+// bufferSize returns the size of a buffer big enough for n results, and for every result the query may actually write,
+// which is count. This may be more than n e.g. for a scalar query of a constant that returns a list.
+func bufferSize(n int, count int) int {
+    if n < count { n = count }
+    if n < maxResults { return maxResults }
+    return n
+}
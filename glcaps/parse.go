@@ -60,22 +60,23 @@ func parseCompareCommand(
     return commandCompare{c1, c2, fi, ff, fs}, o, nil
 }
 
-// parseCommand parses and/or/not/ext/GetIntegerv/GetFloatv/if/eq/neq/lt/lte/gt/gte/value commands and returns an
-// offset to the end of the parsed command.
+// parseCommand parses and/or/not/ext/GetIntegerv/GetFloatv/GetIntegeri_v/GetFloati_v/version/if/eq/neq/lt/lte/gt/gte
+// and value commands and returns an offset to the end of the parsed command.
 func parseCommand(tag string, _offset int) (c command, next int, err error) {
     var start, offset = parseAtom(tag, _offset)
-    if offset < 0 { return c, 0, fmt.Errorf("expected command") }
+    if offset < 0 { return c, 0, fail(ErrSyntax, "expected command") }
+    var i, f = operator.Int.Binary, operator.Float32.Binary
     
     switch start {
         case "and": return parseBinaryBooleanCommand(tag, offset, operator.Bool.Binary.And)
         case "or":  return parseBinaryBooleanCommand(tag, offset, operator.Bool.Binary.Or)
         
-        case "eq":  return parseCompareCommand(tag, offset, operator.Int.Binary.Eq,  operator.Float32.Binary.Eq,  operationStringEq)
-        case "neq": return parseCompareCommand(tag, offset, operator.Int.Binary.Eq,  operator.Float32.Binary.Neq, operationStringNeq)
-        case "lt":  return parseCompareCommand(tag, offset, operator.Int.Binary.Lt,  operator.Float32.Binary.Lt,  nil)
-        case "lte": return parseCompareCommand(tag, offset, operator.Int.Binary.Lte, operator.Float32.Binary.Lte, nil)
-        case "gt":  return parseCompareCommand(tag, offset, operator.Int.Binary.Gt,  operator.Float32.Binary.Gt,  nil)
-        case "gte": return parseCompareCommand(tag, offset, operator.Int.Binary.Gte, operator.Float32.Binary.Gte, nil)
+        case "eq":  return parseCompareCommand(tag, offset, i.Eq,  f.Eq,  operationStringEq)
+        case "neq": return parseCompareCommand(tag, offset, i.Neq, f.Neq, operationStringNeq)
+        case "lt":  return parseCompareCommand(tag, offset, i.Lt,  f.Lt,  nil)
+        case "lte": return parseCompareCommand(tag, offset, i.Lte, f.Lte, nil)
+        case "gt":  return parseCompareCommand(tag, offset, i.Gt,  f.Gt,  nil)
+        case "gte": return parseCompareCommand(tag, offset, i.Gte, f.Gte, nil)
 
         case "if":
             var ac, ao, ae = parseCommand(tag, offset)
@@ -96,23 +97,44 @@ func parseCommand(tag string, _offset int) (c command, next int, err error) {
             
         case "ext":
             var c1, o = parseAtom(tag, offset)
-            if o < 0 { return c, 0, fmt.Errorf("expected name after ext") }
+            if o < 0 { return c, 0, fail(ErrSyntax, "expected name after ext") }
             return commandExt{c1}, o, nil
         
         case "GetString":
             var c1, o = parseAtom(tag, offset)
-            if o < 0 { return c, 0, fmt.Errorf("expected name after GetString") }
+            if o < 0 { return c, 0, fail(ErrSyntax, "expected name after GetString") }
+            if _, err := lookupConstant(c1); err != nil { return c, 0, err }
             return commandGetString{c1}, o, nil
             
         case "GetIntegerv":
             var c1, o = parseAtom(tag, offset)
-            if o < 0 { return c, 0, fmt.Errorf("expected name after GetIntegerv") }
+            if o < 0 { return c, 0, fail(ErrSyntax, "expected name after GetIntegerv") }
+            if _, err := lookupConstant(c1); err != nil { return c, 0, err }
             return commandGetIntegerv{c1}, o, nil
         
         case "GetFloatv":
             var c1, o = parseAtom(tag, offset)
-            if o < 0 { return c, 0, fmt.Errorf("expected name after GetFloatv") }
+            if o < 0 { return c, 0, fail(ErrSyntax, "expected name after GetFloatv") }
+            if _, err := lookupConstant(c1); err != nil { return c, 0, err }
             return commandGetFloatv{c1}, o, nil
+        
+        case "version":
+            var c1, o = parseAtom(tag, offset)
+            if o < 0 { return c, 0, fail(ErrSyntax, "expected name after version") }
+            if _, err := lookupConstant(c1); err != nil { return c, 0, err }
+            return commandVersion{c1}, o, nil
+        
+        case "GetIntegeri_v":
+            var c1, o = parseAtom(tag, offset)
+            if o < 0 { return c, 0, fail(ErrSyntax, "expected name after GetIntegeri_v") }
+            if _, err := lookupConstant(c1); err != nil { return c, 0, err }
+            return commandGetIntegeriv{c1}, o, nil
+        
+        case "GetFloati_v":
+            var c1, o = parseAtom(tag, offset)
+            if o < 0 { return c, 0, fail(ErrSyntax, "expected name after GetFloati_v") }
+            if _, err := lookupConstant(c1); err != nil { return c, 0, err }
+            return commandGetFloativ{c1}, o, nil
             
         default:
             return commandValue{start}, offset, nil
@@ -138,7 +160,7 @@ func parseCompareRequirement(
     fs func(string, string) bool,
 ) (r requirement, next int, _err error) {
     var r1, o = parseAtom(tag, offset)
-    if o < 0 { return r, 0, fmt.Errorf("expected constant after comparison") }
+    if o < 0 { return r, 0, fail(ErrSyntax, "expected constant after comparison") }
     return requirementComparison{
         constant:   r1,
         symbol:     symbol,
@@ -153,20 +175,30 @@ func parseCompareRequirement(
 func parseRequirement(tag string, _offset int) (r requirement, next int, err error) {
     var start, offset = parseAtom(tag, _offset)
     if offset < 0 { return r, -1, nil }
+    var i, f = operator.Int.Binary, operator.Float32.Binary
     
     switch start {
         case "required":
             return requirementRequired{}, offset, nil
         
-        case "eq":  return parseCompareRequirement(tag, offset, "=",  operator.Int.Binary.Eq,  operator.Float32.Binary.Eq,  operationStringEq)
-        case "neq": return parseCompareRequirement(tag, offset, "!=", operator.Int.Binary.Neq, operator.Float32.Binary.Neq, operationStringNeq)
-        case "lt":  return parseCompareRequirement(tag, offset, "<",  operator.Int.Binary.Lt,  operator.Float32.Binary.Lt,  nil)
-        case "lte": return parseCompareRequirement(tag, offset, "<=", operator.Int.Binary.Lte, operator.Float32.Binary.Lte, nil)
-        case "gt":  return parseCompareRequirement(tag, offset, ">",  operator.Int.Binary.Gt,  operator.Float32.Binary.Gt,  nil)
-        case "gte": return parseCompareRequirement(tag, offset, ">=", operator.Int.Binary.Gte, operator.Float32.Binary.Gte, nil)
+        case "profile":
+            var r1, o = parseAtom(tag, offset)
+            switch r1 {
+                case "core", "compatibility", "es":
+                    return requirementProfile{r1}, o, nil
+                default:
+                    return r, 0, fail(ErrSyntax, "expected core, compatibility or es after profile")
+            }
+        
+        case "eq":  return parseCompareRequirement(tag, offset, "=",  i.Eq,  f.Eq,  operationStringEq)
+        case "neq": return parseCompareRequirement(tag, offset, "!=", i.Neq, f.Neq, operationStringNeq)
+        case "lt":  return parseCompareRequirement(tag, offset, "<",  i.Lt,  f.Lt,  nil)
+        case "lte": return parseCompareRequirement(tag, offset, "<=", i.Lte, f.Lte, nil)
+        case "gt":  return parseCompareRequirement(tag, offset, ">",  i.Gt,  f.Gt,  nil)
+        case "gte": return parseCompareRequirement(tag, offset, ">=", i.Gte, f.Gte, nil)
         
         default:
-            return r, 0, fmt.Errorf("unknown requirement: '%s'", start)
+            return r, 0, fail(ErrSyntax, "unknown requirement: '%s'", start)
     }
 }
 
@@ -192,7 +224,7 @@ func parseTag(t string) (tag, error) {
     if err != nil { return tag{}, err }
     
     if index < len(left) && strings.TrimSpace(left[index + 1:]) != "" {
-        return tag{}, fmt.Errorf("unexpected trailing string after end of command: '%s'", left[index:])
+        return tag{}, fail(ErrSyntax, "unexpected trailing string after end of command: '%s'", left[index:])
     }
     
     var requirements, rerr = parseRequirements(right)
@@ -214,6 +246,7 @@ func checkBoolRequirements(field reflect.StructField, result bool, rs []requirem
             Tag:   field.Tag.Get("glcaps"),
             Requirement: r,
             Message: err.Error(),
+            Err: err,
         })
     }
     
@@ -230,6 +263,7 @@ func checkIntRequirements(field reflect.StructField, result int, rs []requiremen
             Tag:   field.Tag.Get("glcaps"),
             Requirement: r,
             Message: err.Error(),
+            Err: err,
         })
     }
     
@@ -246,6 +280,7 @@ func checkFloatRequirements(field reflect.StructField, result float32, rs []requ
             Tag:   field.Tag.Get("glcaps"),
             Requirement: r,
             Message: err.Error(),
+            Err: err,
         })
     }
     
@@ -262,13 +297,164 @@ func checkStringRequirements(field reflect.StructField, result string, rs []requ
             Tag:   field.Tag.Get("glcaps"),
             Requirement: r,
             Message: err.Error(),
+            Err: err,
+        })
+    }
+    
+    return errors
+}
+
+func checkVersionRequirements(field reflect.StructField, result Version, rs []requirement) (errors Errors) {
+    for _, r := range rs {
+        var err = r.evalVersion(field.Name, result)
+        if err == nil { continue }
+        
+        errors.append(Error{
+            Field: field.Name,
+            Tag:   field.Tag.Get("glcaps"),
+            Requirement: r,
+            Message: err.Error(),
+            Err: err,
         })
     }
     
     return errors
 }
 
-func parseStructField(binding *Binding, extensions []string, field reflect.StructField, setter reflect.Value, value interface{}) (errors Errors) {
+func checkIntsRequirements(field reflect.StructField, results []int, rs []requirement) (errors Errors) {
+    for i, result := range results {
+        for _, r := range rs {
+            var err = r.evalInt(fmt.Sprintf("%s[%d]", field.Name, i), result)
+            if err == nil { continue }
+            
+            errors.append(Error{
+                Field: field.Name,
+                Tag:   field.Tag.Get("glcaps"),
+                Requirement: r,
+                Message: err.Error(),
+                Err: err,
+            })
+        }
+    }
+    
+    return errors
+}
+
+func checkFloatsRequirements(field reflect.StructField, results []float32, rs []requirement) (errors Errors) {
+    for i, result := range results {
+        for _, r := range rs {
+            var err = r.evalFloat(fmt.Sprintf("%s[%d]", field.Name, i), result)
+            if err == nil { continue }
+            
+            errors.append(Error{
+                Field: field.Name,
+                Tag:   field.Tag.Get("glcaps"),
+                Requirement: r,
+                Message: err.Error(),
+                Err: err,
+            })
+        }
+    }
+    
+    return errors
+}
+
+// numeric returns the class of a numeric kind: 'i' for a signed integer, 'u' for an unsigned integer, 'f' for a
+// float, or zero for anything else.
+func numeric(kind reflect.Kind) byte {
+    switch kind {
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+            return 'i'
+        case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+            return 'u'
+        case reflect.Float32, reflect.Float64:
+            return 'f'
+        default:
+            return 0
+    }
+}
+
+// checkOverflow returns an error wrapping operator.ErrorOverflow if an integer result can't be stored in a value of
+// type t, a signed or unsigned integer type of any size.
+func checkOverflow(name string, t reflect.Type, result int) error {
+    var v = reflect.Zero(t)
+    var overflow bool
+    
+    if numeric(t.Kind()) == 'i' {
+        overflow = v.OverflowInt(int64(result))
+    } else {
+        overflow = (result < 0) || v.OverflowUint(uint64(result))
+    }
+    
+    if overflow { return fail(operator.ErrorOverflow, "%s is %d, which overflows %s", name, result, t) }
+    return nil
+}
+
+// setInt sets a signed or unsigned integer value of any size.
+func setInt(setter reflect.Value, result int) {
+    if numeric(setter.Kind()) == 'i' {
+        setter.SetInt(int64(result))
+    } else {
+        setter.SetUint(uint64(result))
+    }
+}
+
+// parseVectorField evaluates a command for an array or slice field of integers or floats
+func parseVectorField(
+    binding *Binding,
+    extensions []string,
+    field reflect.StructField,
+    setter reflect.Value,
+    t tag,
+) (Errors, error) {
+    var errors Errors
+    var n = 0 // every value, for a slice
+    if field.Type.Kind() == reflect.Array { n = field.Type.Len() }
+    
+    var elem = field.Type.Elem()
+    if numeric(elem.Kind()) == 0 {
+        var message = "unsupported field type %s (want an array or slice of integers or floats)"
+        return nil, fail(ErrKind, message, field.Type)
+    }
+    
+    var c, ok = t.command.(vectorCommand)
+    if !ok { return nil, fail(ErrKind, "%s is not a query that returns multiple values", field.Tag.Get("glcaps")) }
+    
+    switch numeric(elem.Kind()) {
+        case 'i': fallthrough
+        case 'u':
+            var results, err = c.evalInts(binding, extensions, n)
+            if err != nil { return nil, err }
+            errors.append(checkIntsRequirements(field, results, t.requirements)...)
+            
+            for i, result := range results {
+                var err = checkOverflow(fmt.Sprintf("%s[%d]", field.Name, i), elem, result)
+                if err != nil { return errors, err }
+            }
+            
+            if n == 0 { setter.Set(reflect.MakeSlice(field.Type, len(results), len(results))) }
+            for i, result := range results { setInt(setter.Index(i), result) }
+            
+        case 'f':
+            var results, err = c.evalFloats(binding, extensions, n)
+            if err != nil { return nil, err }
+            errors.append(checkFloatsRequirements(field, results, t.requirements)...)
+            
+            if n == 0 { setter.Set(reflect.MakeSlice(field.Type, len(results), len(results))) }
+            for i, result := range results { setter.Index(i).SetFloat(float64(result)) }
+    }
+    
+    return errors, nil
+}
+
+var versionType = reflect.TypeOf(Version{})
+
+func parseStructField(
+    binding *Binding,
+    extensions []string,
+    field reflect.StructField,
+    setter reflect.Value,
+) (errors Errors) {
 
     var parse = func(field reflect.StructField) (_tag tag, ok bool) {
         // fmt.Printf("got float32 %s %s %s\n", field.Name, field.Tag, field.Tag.Get("glcaps"))
@@ -281,6 +467,7 @@ func parseStructField(binding *Binding, extensions []string, field reflect.Struc
                 Field: field.Name,
                 Tag:   glcapstag,
                 Message: fmt.Sprintf("tag parse error: %v", err),
+                Err: err,
             })
             return t, false
         }
@@ -290,32 +477,75 @@ func parseStructField(binding *Binding, extensions []string, field reflect.Struc
     
     var kind = field.Type.Kind()
     
-    if kind == reflect.Struct {
+    // an unexported field can't be set, so is an error only if it is tagged
+    if field.PkgPath != "" {
+        if glcapstag, exists := field.Tag.Lookup("glcaps"); exists {
+            var err = fail(ErrKind, "field %s is unexported", field.Name)
+            errors.append(Error{Field: field.Name, Tag: glcapstag, Message: err.Error(), Err: err})
+        }
+    } else if (kind == reflect.Struct) && (field.Type != versionType) {
         errors.append(parseStruct(binding, extensions, setter)...)
     } else {
         var t, ok = parse(field)
         if ok {
+            var failed = func(err error) {
+                errors.append(Error{
+                    Field: field.Name,
+                    Tag:   field.Tag.Get("glcaps"),
+                    Message: err.Error(),
+                    Err: err,
+                })
+            }
+            
             switch kind {
                 case reflect.Bool:
-                    var result = t.command.evalBool(binding, extensions)
+                    var result, err = t.command.evalBool(binding, extensions)
+                    if err != nil { failed(err); break }
                     errors.append(checkBoolRequirements(field, result, t.requirements)...)
                     setter.SetBool(result)
                     
-                case reflect.Int:
-                    var result = t.command.evalInt(binding, extensions)
+                case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64: fallthrough
+                case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+                    var result, err = t.command.evalInt(binding, extensions)
+                    if err != nil { failed(err); break }
                     errors.append(checkIntRequirements(field, result, t.requirements)...)
-                    setter.SetInt(int64(result))
+                    
+                    err = checkOverflow(field.Name, field.Type, result)
+                    if err != nil { failed(err); break }
+                    setInt(setter, result)
                     
                 case reflect.Float32: fallthrough
                 case reflect.Float64:
-                    var result = t.command.evalFloat(binding, extensions)
+                    var result, err = t.command.evalFloat(binding, extensions)
+                    if err != nil { failed(err); break }
                     errors.append(checkFloatRequirements(field, result, t.requirements)...)
                     setter.SetFloat(float64(result))
 
                 case reflect.String:
-                    var result = t.command.evalString(binding, extensions)
+                    var result, err = t.command.evalString(binding, extensions)
+                    if err != nil { failed(err); break }
                     errors.append(checkStringRequirements(field, result, t.requirements)...)
                     setter.SetString(result)
+
+                case reflect.Struct: // a Version
+                    var result, err = evalVersion(t.command, binding, extensions)
+                    if err != nil { failed(err); break }
+                    errors.append(checkVersionRequirements(field, result, t.requirements)...)
+                    setter.Set(reflect.ValueOf(result))
+                
+                case reflect.Array: fallthrough
+                case reflect.Slice:
+                    var results, err = parseVectorField(binding, extensions, field, setter, t)
+                    errors.append(results...)
+                    if err != nil { failed(err); break }
+                
+                default:
+                    failed(fail(ErrKind, "unsupported field type %s", field.Type))
+            }
+            
+            // with a strict profile, anything this field queried that isn't in the profile
+            if (binding != nil) && (binding.missing != nil) {
+                for _, name := range binding.missing() { failed(fail(ErrMissing, "%s is not in the profile", name)) }
             }
         }
     }
@@ -326,11 +556,13 @@ func parseStructField(binding *Binding, extensions []string, field reflect.Struc
 func parseStruct(binding *Binding, extensions []string, s reflect.Value) (errors Errors) {
     
     if s.Kind() != reflect.Struct {
-        panic("target must be a struct or pointer to struct")
+        var err = fail(ErrKind, "target must be a struct or pointer to struct")
+        errors.append(Error{Message: err.Error(), Err: err})
+        return errors
     }
     
     for i := 0; i < s.NumField(); i++ {
-        errors.append(parseStructField(binding, extensions, s.Type().Field(i), s.Field(i), s.Field(i).Interface())...)
+        errors.append(parseStructField(binding, extensions, s.Type().Field(i), s.Field(i))...)
     }
     
     return errors
@@ -351,6 +583,9 @@ func parseStruct(binding *Binding, extensions []string, s reflect.Value) (errors
 //    ext GL_EXT_name                - return true if the given extension is supported
 //    GetIntegerv GL_name            - lookup and return an integer value
 //    GetFloatv GL_name              - lookup and return a float value
+//    GetIntegeri_v GL_name          - lookup and return an indexed integer value
+//    GetFloati_v GL_name            - lookup and return an indexed float value
+//    version GL_name                - lookup and parse GL_VERSION or GL_SHADING_LANGUAGE_VERSION as a Version
 //    if command1 command2 command3  - if command1 is true, return the result of command2 otherwise return command3
 //    eq|neq|lt|lte|gt|gte command1 command2 - return true if command1 ==/!=/</<=/>/>= command2 respectively
 //    value                          - a value literal (e.g. true, false, 123, 1.23, 128KiB)
@@ -359,9 +594,47 @@ func parseStruct(binding *Binding, extensions []string, s reflect.Value) (errors
 //
 //    required                       - generate an error if the result is not true
 //    eq|neq|lt|lte|gt|gte value     - generate an error if the command is not ==, !=, <, <=, >, >= value respectively
+//    profile core|compatibility|es  - generate an error if a Version does not have the given profile
+//
+// The result of the version command can be stored in a field of type Version, or compared with a version literal
+// such as 4.3 for OpenGL or ES3.1 for OpenGL ES. An OpenGL version never compares equal to, less than, or greater
+// than an OpenGL ES version. For example:
+//
+//    Version    Version `glcaps:"version GL_VERSION; gte 3.3 profile core"`
+//    HasCompute bool    `glcaps:"or gte version GL_VERSION 4.3 gte version GL_VERSION ES3.1"`
+//
+// A field may be a bool, a string, a Version, or any integer or float type. An integer result that doesn't fit in the
+// field (for example, a negative result for a uint field) is reported as an Error that wraps operator.ErrorOverflow,
+// and any other type is reported as an Error that wraps ErrKind.
+//
+// A field may also be an array or slice of integers or floats, for a GetIntegerv, GetFloatv, GetIntegeri_v or
+// GetFloati_v command that returns several values (e.g. GL_MAX_VIEWPORT_DIMS) or accepts several indexes (e.g.
+// GL_MAX_COMPUTE_WORK_GROUP_COUNT). An array is filled up to its length, and a slice is set to every value. An array
+// longer than the number of values is an Error that wraps ErrKind. Each requirement then applies to every element:
 //
+//    MaxViewportDims [2]int `glcaps:"GetIntegerv GL_MAX_VIEWPORT_DIMS; gte 4096"`
+//
+// Parse does not panic on a bad tag or a missing OpenGL context. Instead, each failure is reported as an Error that
+// wraps one of ErrSyntax (a malformed tag), ErrKind (a command or requirement that doesn't match the type of the
+// field), ErrUnknownConstant (a GL_ name that doesn't exist), ErrNoContext (no current OpenGL context) or
+// ErrRequirement (a requirement that isn't met), or operator.ErrorOverflow as above, and can be tested with
+// errors.Is. A field that fails to parse or evaluate is left unchanged.
 func Parse(binding *Binding, target interface{}) (extensions Extensions, errors Errors) {
-    extensions = binding.QueryExtensions()
-    return extensions, parseStruct(binding, extensions, reflect.ValueOf(target).Elem())
+    var v = reflect.ValueOf(target)
+    if (v.Kind() != reflect.Ptr) || v.IsNil() {
+        var err = fail(ErrKind, "target must be a pointer to a struct")
+        errors.append(Error{Message: err.Error(), Err: err})
+        return nil, errors
+    }
+    
+    var err error
+    extensions, err = binding.QueryExtensionsE()
+    if err != nil {
+        errors.append(Error{Message: err.Error(), Err: err})
+        return nil, errors
+    }
+    if binding.missing != nil { binding.missing() } // only count queries for each field
+    
+    return extensions, parseStruct(binding, extensions, v.Elem())
 }
 
@@ -0,0 +1,348 @@
+package glcaps
+
+import (
+    "embed"
+    "encoding/json"
+    "fmt"
+    "math"
+    "path"
+    "reflect"
+    "sort"
+    "strings"
+    "sync"
+    "unsafe"
+)
+
+// Profile is a record of the results of OpenGL queries, by constant name, so that a struct can be parsed without an
+// OpenGL context (e.g. to test it in CI). A Profile is usually stored as JSON.
+//
+// Record a Profile from a real OpenGL context with Profile.Record, or use a bundled ReferenceProfile. Replay it with
+// BindingFromProfile.
+type Profile struct {
+    Name            string                 `json:"name,omitempty"`
+    Integers        map[string][]int32     `json:"integers,omitempty"`        // GetIntegerv results
+    Floats          map[string][]float32   `json:"floats,omitempty"`          // GetFloatv results
+    IndexedIntegers map[string][][]int32   `json:"indexedIntegers,omitempty"` // GetIntegeri_v results by index
+    IndexedFloats   map[string][][]float32 `json:"indexedFloats,omitempty"`   // GetFloati_v results by index
+    Strings         map[string]string      `json:"strings,omitempty"`         // GetString results
+    Extensions      []string               `json:"extensions"`                // GetStringi GL_EXTENSIONS results
+}
+
+// Record returns a Binding that calls b, and also records the result of every query in p. For example, parse a struct
+// with the returned Binding, and then save p as JSON to record every value that struct depends on.
+//
+// The number of extensions is not recorded, because it is the length of the recorded list of extensions.
+func (p *Profile) Record(b *Binding) *Binding {
+    var r = &Binding{}
+
+    if b.GetIntegerv != nil {
+        r.GetIntegerv = func(name uint32, data *int32) {
+            b.GetIntegerv(name, data)
+            if name == glconstants["GL_NUM_EXTENSIONS"] { return }
+
+            var key = constantName(name)
+            var n, _ = resultCount(b, key)
+            if p.Integers == nil { p.Integers = make(map[string][]int32) }
+            p.Integers[key] = append([]int32(nil), int32s(data, n)...)
+        }
+    }
+
+    if b.GetFloatv != nil {
+        r.GetFloatv = func(name uint32, data *float32) {
+            b.GetFloatv(name, data)
+
+            var key = constantName(name)
+            var n, _ = resultCount(b, key)
+            if p.Floats == nil { p.Floats = make(map[string][]float32) }
+            p.Floats[key] = append([]float32(nil), float32s(data, n)...)
+        }
+    }
+
+    if b.GetIntegeri_v != nil {
+        r.GetIntegeri_v = func(name uint32, index uint32, data *int32) {
+            b.GetIntegeri_v(name, index, data)
+
+            var key = constantName(name)
+            if p.IndexedIntegers == nil { p.IndexedIntegers = make(map[string][][]int32) }
+            var results = p.IndexedIntegers[key]
+            for len(results) <= int(index) { results = append(results, nil) }
+            results[index] = append([]int32(nil), int32s(data, indexResultCount(key))...)
+            p.IndexedIntegers[key] = results
+        }
+    }
+
+    if b.GetFloati_v != nil {
+        r.GetFloati_v = func(name uint32, index uint32, data *float32) {
+            b.GetFloati_v(name, index, data)
+
+            var key = constantName(name)
+            if p.IndexedFloats == nil { p.IndexedFloats = make(map[string][][]float32) }
+            var results = p.IndexedFloats[key]
+            for len(results) <= int(index) { results = append(results, nil) }
+            results[index] = append([]float32(nil), float32s(data, indexResultCount(key))...)
+            p.IndexedFloats[key] = results
+        }
+    }
+
+    if b.GetString != nil {
+        r.GetString = func(name uint32) string {
+            var result = b.GetString(name)
+            if p.Strings == nil { p.Strings = make(map[string]string) }
+            p.Strings[constantName(name)] = result
+            return result
+        }
+    }
+
+    if b.GetStringi != nil {
+        r.GetStringi = func(name uint32, index uint32) string {
+            var result = b.GetStringi(name, index)
+            if name != glconstants["GL_EXTENSIONS"] { return result }
+
+            for len(p.Extensions) <= int(index) { p.Extensions = append(p.Extensions, "") }
+            p.Extensions[index] = result
+            return result
+        }
+    }
+
+    return r
+}
+
+// BindingFromProfile returns a Binding that replays the results of queries recorded in a Profile, without an OpenGL
+// context. Like OpenGL, a query for a constant that isn't in the profile leaves the result unchanged.
+//
+// An integer query for a value recorded as a float, or a float query for a value recorded as an integer, is converted
+// as in OpenGL. Counts such as GL_NUM_EXTENSIONS or GL_NUM_COMPRESSED_TEXTURE_FORMATS are the length of the matching
+// list, if not given.
+//
+// Like OpenGL, each query writes every recorded value, so the caller must provide a buffer big enough for every
+// result of that constant, as glcaps does. The returned error wraps ErrUnknownConstant for a name that is not an
+// OpenGL constant, or ErrKind for a list that is too long or doesn't match its count.
+func BindingFromProfile(profile *Profile) (*Binding, error) {
+    return bindingFromProfile(profile, false)
+}
+
+// StrictBindingFromProfile is like BindingFromProfile, but Parse also returns an Error wrapping ErrMissing for each
+// field that queries a constant, index or string that isn't in the profile, instead of quietly setting it to zero.
+// This checks that a profile has everything a struct depends on.
+func StrictBindingFromProfile(profile *Profile) (*Binding, error) {
+    return bindingFromProfile(profile, true)
+}
+
+func bindingFromProfile(profile *Profile, strict bool) (*Binding, error) {
+    var ints    = make(map[uint32][]int32)
+    var floats  = make(map[uint32][]float32)
+    var iints   = make(map[uint32][][]int32)
+    var ifloats = make(map[uint32][][]float32)
+    var strs    = make(map[uint32]string)
+    var err error
+
+    var id = func(name string) uint32 {
+        var result, e = lookupConstant(name)
+        if (e != nil) && (err == nil) { err = e }
+        return result
+    }
+
+    for k, v := range profile.Integers        { ints[id(k)]    = v }
+    for k, v := range profile.Floats          { floats[id(k)]  = v }
+    for k, v := range profile.IndexedIntegers { iints[id(k)]   = v }
+    for k, v := range profile.IndexedFloats   { ifloats[id(k)] = v }
+    for k, v := range profile.Strings         { strs[id(k)]    = v }
+    if err != nil { return nil, err }
+
+    var extensions = append([]string(nil), profile.Extensions...)
+    ints[glconstants["GL_NUM_EXTENSIONS"]] = []int32{int32(len(extensions))}
+
+    // Every list is written in full, like OpenGL, so must fit in a buffer of the size used by glcaps. A list with a
+    // count must match its count, because that's the size of the buffer, and anything else must fit in maxResults.
+    var checkLength = func(k string, length int) error {
+        if count, ok := glcounts[k]; ok {
+            var n = ints[glconstants[count]]
+            if n == nil { ints[glconstants[count]] = []int32{int32(length)}; return nil }
+            if (len(n) != 1) || (int(n[0]) != length) {
+                return fail(ErrKind, "profile has %d values for %s, but %s is %v", length, k, count, n)
+            }
+        } else if length > maxResults {
+            return fail(ErrKind, "profile has too many values for %s", k)
+        }
+        return nil
+    }
+    for k, v := range profile.Integers {
+        if err := checkLength(k, len(v)); err != nil { return nil, err }
+    }
+    for k, v := range profile.Floats {
+        if err := checkLength(k, len(v)); err != nil { return nil, err }
+    }
+    for k, vs := range profile.IndexedIntegers {
+        for _, v := range vs {
+            if len(v) > maxResults { return nil, fail(ErrKind, "profile has too many values for %s", k) }
+        }
+    }
+    for k, vs := range profile.IndexedFloats {
+        for _, v := range vs {
+            if len(v) > maxResults { return nil, fail(ErrKind, "profile has too many values for %s", k) }
+        }
+    }
+
+    var mutex sync.Mutex
+    var missing []string
+    var miss = func(name string) {
+        mutex.Lock()
+        missing = append(missing, name)
+        mutex.Unlock()
+    }
+
+    var binding = &Binding{
+        GetIntegerv: func(name uint32, data *int32) {
+            if v, ok := ints[name]; ok {
+                copy(int32s(data, len(v)), v)
+            } else if v, ok := floats[name]; ok {
+                var results = int32s(data, len(v))
+                for i := range v { results[i] = int32(math.Round(float64(v[i]))) }
+            } else {
+                miss(constantName(name))
+            }
+        },
+        GetFloatv: func(name uint32, data *float32) {
+            if v, ok := floats[name]; ok {
+                copy(float32s(data, len(v)), v)
+            } else if v, ok := ints[name]; ok {
+                var results = float32s(data, len(v))
+                for i := range v { results[i] = float32(v[i]) }
+            } else {
+                miss(constantName(name))
+            }
+        },
+        GetIntegeri_v: func(name uint32, index uint32, data *int32) {
+            if v := iints[name]; int(index) < len(v) {
+                copy(int32s(data, len(v[index])), v[index])
+            } else {
+                miss(fmt.Sprintf("%s[%d]", constantName(name), index))
+            }
+        },
+        GetFloati_v: func(name uint32, index uint32, data *float32) {
+            if v := ifloats[name]; int(index) < len(v) {
+                copy(float32s(data, len(v[index])), v[index])
+            } else {
+                miss(fmt.Sprintf("%s[%d]", constantName(name), index))
+            }
+        },
+        GetString: func(name uint32) string {
+            var result, ok = strs[name]
+            if !ok { miss(constantName(name)) }
+            return result
+        },
+        GetStringi: func(name uint32, index uint32) string {
+            if (name != glconstants["GL_EXTENSIONS"]) || (int(index) >= len(extensions)) {
+                miss(fmt.Sprintf("%s[%d]", constantName(name), index))
+                return ""
+            }
+            return extensions[index]
+        },
+    }
+
+    if strict {
+        binding.missing = func() []string {
+            mutex.Lock()
+            defer mutex.Unlock()
+            var result = missing
+            missing = nil
+            return result
+        }
+    }
+
+    return binding, nil
+}
+
+//go:embed profiles/*.json
+var referenceProfiles embed.FS
+
+// ReferenceProfiles returns the name of every bundled reference profile, in sorted order, for use with
+// ReferenceProfile.
+func ReferenceProfiles() []string {
+    var entries, _ = referenceProfiles.ReadDir("profiles")
+    var names = make([]string, 0, len(entries))
+    for _, entry := range entries {
+        names = append(names, strings.TrimSuffix(entry.Name(), ".json"))
+    }
+    sort.Strings(names)
+    return names
+}
+
+// ReferenceProfile returns a bundled reference profile by name. Each reference profile is the minimum that any
+// implementation of a version of OpenGL or OpenGL ES must support, with no extensions. The profiles are:
+//
+//    gl-3.3-core - "GL 3.3 core minimum"
+//    gles-3.0    - "GLES 3.0 minimum"
+//
+// For example, to check that a struct can be parsed on any OpenGL 3.3 core implementation:
+//
+//    var profile, _ = glcaps.ReferenceProfile("gl-3.3-core")
+//    var binding, _ = glcaps.StrictBindingFromProfile(profile)
+//    var _, errors = glcaps.Parse(binding, &caps)
+//
+// A reference profile only has the constants in its version of OpenGL. With BindingFromProfile, like OpenGL, a field
+// that queries anything else (such as GL_MAX_ELEMENT_INDEX, from OpenGL 4.3) is quietly set to zero, so use
+// StrictBindingFromProfile to report it as an Error wrapping ErrMissing.
+//
+func ReferenceProfile(name string) (*Profile, error) {
+    var data, err = referenceProfiles.ReadFile(path.Join("profiles", name+".json"))
+    if err != nil { return nil, fmt.Errorf("no such reference profile: '%s'", name) }
+
+    var p Profile
+    err = json.Unmarshal(data, &p)
+    if err != nil { return nil, fmt.Errorf("error reading reference profile '%s': %v", name, err) }
+    return &p, nil
+}
+
+// indexResultCount returns the number of values returned by each index of glGetIntegeri_v or glGetFloati_v for the
+// named constant.
+func indexResultCount(name string) int {
+    if n, ok := glresults[name]; ok { return n }
+    return 1
+}
+
+// int32s returns a slice of n values starting at data, like a C array
+func int32s(data *int32, n int) (results []int32) {
+    if n <= 0 { return nil }
+    var h = (*reflect.SliceHeader)(unsafe.Pointer(&results))
+    h.Data, h.Len, h.Cap = uintptr(unsafe.Pointer(data)), n, n
+    return results
+}
+
+// float32s returns a slice of n values starting at data, like a C array
+func float32s(data *float32, n int) (results []float32) {
+    if n <= 0 { return nil }
+    var h = (*reflect.SliceHeader)(unsafe.Pointer(&results))
+    h.Data, h.Len, h.Cap = uintptr(unsafe.Pointer(data)), n, n
+    return results
+}
+
+var constantNames map[uint32]string
+var constantNamesOnce sync.Once
+
+// constantName returns the name of an OpenGL constant by value. Where several constants have the same value, it
+// prefers a name glcaps knows has several results, then a name without a vendor suffix, then the shortest name.
+func constantName(id uint32) string {
+    constantNamesOnce.Do(func() {
+        var rank = func(name string) string {
+            var known, vendor = "1", "0"
+            if _, ok := glresults[name]; ok { known = "0" }
+            if _, ok := glcounts[name]; ok { known = "0" }
+            if _, ok := glindexes[name]; ok { known = "0" }
+            for _, suffix := range []string{"_ARB", "_EXT", "_KHR", "_OES", "_NV", "_AMD", "_ATI", "_APPLE", "_INTEL",
+                "_SGI", "_SGIS", "_SGIX", "_MESA", "_IBM", "_HP", "_SUN", "_IMG", "_QCOM", "_ANGLE", "_ARM", "_NVX"} {
+                if strings.HasSuffix(name, suffix) { vendor = "1" }
+            }
+            return fmt.Sprintf("%s%s%04d%s", known, vendor, len(name), name)
+        }
+
+        constantNames = make(map[uint32]string)
+        for name, value := range glconstants {
+            if existing, ok := constantNames[value]; ok && (rank(existing) < rank(name)) { continue }
+            constantNames[value] = name
+        }
+    })
+
+    if name, ok := constantNames[id]; ok { return name }
+    return fmt.Sprintf("0x%04X", id)
+}
@@ -0,0 +1,162 @@
+package glcaps
+
+import (
+    "encoding/json"
+    "errors"
+    "reflect"
+    "testing"
+)
+
+type profileCaps struct {
+    Version           Version   `glcaps:"version GL_VERSION"`
+    GLSLVersion       Version   `glcaps:"version GL_SHADING_LANGUAGE_VERSION"`
+    Vendor            string    `glcaps:"GetString GL_VENDOR"`
+    HasFoo            bool      `glcaps:"ext GL_EXT_foo"`
+    MaxTextureSize    int       `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"`
+    MaxViewportDims   [2]int    `glcaps:"GetIntegerv GL_MAX_VIEWPORT_DIMS"`
+    CompressedFormats []int     `glcaps:"GetIntegerv GL_COMPRESSED_TEXTURE_FORMATS"`
+    LineWidthRange    []float32 `glcaps:"GetFloatv GL_ALIASED_LINE_WIDTH_RANGE"`
+    WorkGroupCount    [3]int    `glcaps:"GetIntegeri_v GL_MAX_COMPUTE_WORK_GROUP_COUNT"`
+    Viewports         []float32 `glcaps:"GetFloati_v GL_VIEWPORT"`
+}
+
+func TestProfileRoundTrip(t *testing.T) {
+    var expected, result profileCaps
+    var profile Profile
+
+    var _, errs = Parse(profile.Record(testBinding()), &expected)
+    if len(errs) != 0 { t.Fatalf("unexpected errors: %v", errs) }
+
+    var data, err = json.Marshal(&profile)
+    if err != nil { t.Fatalf("unexpected error: %v", err) }
+
+    var loaded Profile
+    err = json.Unmarshal(data, &loaded)
+    if err != nil { t.Fatalf("unexpected error: %v", err) }
+
+    var binding *Binding
+    binding, err = BindingFromProfile(&loaded)
+    if err != nil { t.Fatalf("unexpected error: %v", err) }
+
+    var extensions Extensions
+    extensions, errs = Parse(binding, &result)
+    if len(errs) != 0 { t.Fatalf("unexpected errors: %v", errs) }
+
+    if !reflect.DeepEqual(result, expected) {
+        t.Errorf("got %+v but wanted %+v", result, expected)
+    }
+    if !reflect.DeepEqual(extensions, Extensions{"GL_EXT_foo"}) {
+        t.Errorf("unexpected extensions: %v", extensions)
+    }
+    if _, ok := loaded.Integers["GL_NUM_EXTENSIONS"]; ok {
+        t.Errorf("unexpected GL_NUM_EXTENSIONS in profile")
+    }
+}
+
+func TestReferenceProfiles(t *testing.T) {
+    var names = ReferenceProfiles()
+    if !reflect.DeepEqual(names, []string{"gl-3.3-core", "gles-3.0"}) {
+        t.Fatalf("unexpected reference profiles: %v", names)
+    }
+
+    type caps struct {
+        Version        Version `glcaps:"version GL_VERSION; required"`
+        MaxTextureSize int     `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE; gte 1024"`
+        NumFormats     int     `glcaps:"GetIntegerv GL_NUM_COMPRESSED_TEXTURE_FORMATS"`
+        MaxLodBias     float32 `glcaps:"GetFloatv GL_MAX_TEXTURE_LOD_BIAS"`
+        MaxLodBiasInt  int     `glcaps:"GetIntegerv GL_MAX_TEXTURE_LOD_BIAS"`
+        MaxSamples     float32 `glcaps:"GetFloatv GL_MAX_SAMPLES"`
+    }
+
+    type test struct {
+        name string
+        expected caps
+    }
+
+    var tests = []test{
+        {"gl-3.3-core", caps{Version{3, 3, false, "core", "glcaps reference"},  1024, 0, 2.0, 2, 4.0}},
+        {"gles-3.0",    caps{Version{3, 0, true, "es", "glcaps reference"},     2048, 10, 2.0, 2, 4.0}},
+    }
+
+    for i, test := range tests {
+        var profile, err = ReferenceProfile(test.name)
+        if err != nil { t.Errorf("Test %d: unexpected error %v", i, err); continue }
+
+        var binding *Binding
+        binding, err = StrictBindingFromProfile(profile)
+        if err != nil { t.Errorf("Test %d: unexpected error %v", i, err); continue }
+
+        var result caps
+        var extensions, errs = Parse(binding, &result)
+        if len(errs) != 0 {
+            t.Errorf("Test %d: unexpected errors %v", i, errs)
+        } else if len(extensions) != 0 {
+            t.Errorf("Test %d: unexpected extensions %v", i, extensions)
+        } else if result != test.expected {
+            t.Errorf("Test %d: got %+v but wanted %+v", i, result, test.expected)
+        }
+    }
+
+    var _, err = ReferenceProfile("gl-9.9")
+    if err == nil { t.Errorf("expected an error for an unknown reference profile") }
+}
+
+func TestStrictBindingFromProfile(t *testing.T) {
+    type caps struct {
+        MaxTextureSize  int    `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"`
+        MaxElementIndex int    `glcaps:"GetIntegerv GL_MAX_ELEMENT_INDEX"`
+        Vendor          string `glcaps:"GetString GL_VENDOR"`
+        Renderer        string `glcaps:"GetString GL_RENDERER"`
+    }
+    var profile = &Profile{
+        Integers: map[string][]int32{"GL_MAX_TEXTURE_SIZE": {1024}},
+        Strings:  map[string]string{"GL_VENDOR": "vendor"},
+    }
+
+    // like OpenGL, a query for a missing value leaves the result unchanged, so the field is zero
+    var binding, err = BindingFromProfile(profile)
+    if err != nil { t.Fatal(err) }
+    var result caps
+    if _, errs := Parse(binding, &result); len(errs) != 0 { t.Errorf("unexpected errors %v", errs) }
+    if result != (caps{1024, 0, "vendor", ""}) { t.Errorf("unexpected result %+v", result) }
+
+    // but a strict binding reports them
+    binding, err = StrictBindingFromProfile(profile)
+    if err != nil { t.Fatal(err) }
+    var _, errs = Parse(binding, &caps{})
+    if (len(errs) != 2) || (errs[0].Field != "MaxElementIndex") || (errs[1].Field != "Renderer") {
+        t.Fatalf("unexpected errors %v", errs)
+    }
+    for i, e := range errs {
+        if !errors.Is(e, ErrMissing) { t.Errorf("Test %d: got %v but wanted %v", i, e, ErrMissing) }
+    }
+}
+
+func TestBindingFromProfileErrors(t *testing.T) {
+    type test struct {
+        profile Profile
+        expected error
+    }
+
+    var tooMany = make([]int32, maxResults + 1)
+
+    var tests = []test{
+        {Profile{Strings:  map[string]string{"GL_VERSON": "4.6"}},          ErrUnknownConstant},
+        {Profile{Integers: map[string][]int32{"GL_MAX_VIEWPORT_DIMS": tooMany}}, ErrKind},
+        {Profile{Integers: map[string][]int32{
+            "GL_COMPRESSED_TEXTURE_FORMATS":     {1, 2, 3},
+            "GL_NUM_COMPRESSED_TEXTURE_FORMATS": {2},
+        }}, ErrKind},
+        {Profile{
+            Integers: map[string][]int32{"GL_NUM_COMPRESSED_TEXTURE_FORMATS": {2}},
+            Floats:   map[string][]float32{"GL_COMPRESSED_TEXTURE_FORMATS": {1, 2, 3}},
+        }, ErrKind},
+    }
+
+    for i, test := range tests {
+        var _, err = BindingFromProfile(&test.profile)
+        if !errors.Is(err, test.expected) {
+            t.Errorf("Test %d: got %v but wanted %v", i, err, test.expected)
+        }
+    }
+}
@@ -1,7 +1,6 @@
 package glcaps
 
 import (
-    "fmt"
     "strconv"
     "strings"
 )
@@ -10,21 +9,34 @@ func operationStringEq  (a string,   b string) bool { return a == b }
 func operationStringNeq (a string,   b string) bool { return a != b }
 
 type command interface{
-    evalBool  (b *Binding, extensions Extensions) bool
-    evalInt   (b *Binding, extensions Extensions) int
-    evalFloat (b *Binding, extensions Extensions) float32
-    evalString(b *Binding, extensions Extensions) string
+    evalBool  (b *Binding, extensions Extensions) (bool, error)
+    evalInt   (b *Binding, extensions Extensions) (int, error)
+    evalFloat (b *Binding, extensions Extensions) (float32, error)
+    evalString(b *Binding, extensions Extensions) (string, error)
     hasBoolRepresentation() bool
     hasIntRepresentation() bool
     hasFloatRepresentation() bool
     hasStringRepresentation() bool
 }
 
+// vectorCommand is a command that can return several values, for an array or slice field. n is the length of an
+// array, or zero for a slice to get every value.
+type vectorCommand interface{
+    evalInts  (b *Binding, extensions Extensions, n int) ([]int, error)
+    evalFloats(b *Binding, extensions Extensions, n int) ([]float32, error)
+}
+
+// versionCommand is a command that can return a Version, for a Version field or a version comparison.
+type versionCommand interface{
+    evalVersion(b *Binding, extensions Extensions) (Version, error)
+}
+
 type requirement interface{
-    evalBool  (field string, result bool)    error
-    evalInt   (field string, result int)     error
-    evalFloat (field string, result float32) error
-    evalString(field string, result string) error
+    evalBool   (field string, result bool)    error
+    evalInt    (field string, result int)     error
+    evalFloat  (field string, result float32) error
+    evalString (field string, result string)  error
+    evalVersion(field string, result Version) error
 }
 
 type tag struct {
@@ -32,26 +44,46 @@ type tag struct {
     requirements []requirement
 }
 
+// errors for a command or requirement that has no representation of a kind
+var (
+    errNotBool   = fail(ErrKind, "not a bool")
+    errNotInt    = fail(ErrKind, "not an integer")
+    errNotFloat  = fail(ErrKind, "not a float")
+    errNotString = fail(ErrKind, "not a string")
+)
+
+// lookupConstant returns the value of a named OpenGL constant.
+func lookupConstant(name string) (uint32, error) {
+    var id, exists = glconstants[name]
+    if !exists { return 0, fail(ErrUnknownConstant, "unknown OpenGL constant: '%s'", name) }
+    return id, nil
+}
+
 // ===[ requirementRequired ]================================================================[ requirementRequired ]===
 
 type requirementRequired struct {}
 
 func (r requirementRequired) evalBool(field string, result bool) error {
     if result { return nil }
-    return fmt.Errorf("%s is required", field)
+    return fail(ErrRequirement, "%s is required", field)
 }
 
 func (r requirementRequired) evalInt(field string, result int) error {
-    panic("not an int")
+    return fail(ErrKind, "required does not apply to integer field %s", field)
 }
 
 func (r requirementRequired) evalFloat(field string, result float32) error {
-    panic("not a float")
+    return fail(ErrKind, "required does not apply to float field %s", field)
 }
 
 func (r requirementRequired) evalString(field string, result string) error {
     if len(result) > 0 { return nil }
-    return fmt.Errorf("%s is required", field)
+    return fail(ErrRequirement, "%s is required", field)
+}
+
+func (r requirementRequired) evalVersion(field string, result Version) error {
+    if result.Major > 0 { return nil }
+    return fail(ErrRequirement, "%s is required", field)
 }
 
 // ===[ requirementComparison ]============================================================[ requirementComparison ]===
@@ -65,28 +97,77 @@ type requirementComparison struct {
 }
 
 func (r requirementComparison) evalBool(field string, result bool) error {
-    panic("not a bool")
+    return fail(ErrKind, "comparison %s does not apply to bool field %s", r.symbol, field)
 }
 
 func (r requirementComparison) evalInt(field string, result int) error {
-    var i, err = strconv.ParseInt(r.constant, 10, 32)
-    if err != nil { panic("not an integer constant") }
+    var i, err = strconv.ParseInt(r.constant, 10, 64)
+    if err != nil { return fail(ErrKind, "not an integer constant: '%s'", r.constant) }
     
-    if r.operationi(result, int(i)) { return nil }
-    return fmt.Errorf("%s is %d but must be %s %s", field, result, r.symbol, r.constant)
+    // compare in 64 bits, for a constant that doesn't fit in an int32 (or an int, on 32-bit platforms)
+    var cmp int
+    switch {
+        case int64(result) < i: cmp = -1
+        case int64(result) > i: cmp =  1
+    }
+    if r.operationi(cmp, 0) { return nil }
+    return fail(ErrRequirement, "%s is %d but must be %s %s", field, result, r.symbol, r.constant)
 }
 
 func (r requirementComparison) evalFloat(field string, result float32) error {
     var f, err = strconv.ParseFloat(r.constant, 32)
-    if err != nil { panic("not a float constant") }
+    if err != nil { return fail(ErrKind, "not a float constant: '%s'", r.constant) }
     
     if r.operationf(result, float32(f)) { return nil }
-    return fmt.Errorf("%s is %.2f but must be %s %s", field, result, r.symbol, r.constant)
+    return fail(ErrRequirement, "%s is %.2f but must be %s %s", field, result, r.symbol, r.constant)
 }
 
 func (r requirementComparison) evalString(field string, result string) error {
+    if r.operations == nil {
+        return fail(ErrKind, "comparison %s does not apply to string field %s", r.symbol, field)
+    }
+    
     if r.operations(result, r.constant) { return nil }
-    return fmt.Errorf("%s is %s but must be %s %s", field, result, r.symbol, r.constant)
+    return fail(ErrRequirement, "%s is %s but must be %s %s", field, result, r.symbol, r.constant)
+}
+
+func (r requirementComparison) evalVersion(field string, result Version) error {
+    var v, err = ParseVersion(r.constant)
+    if err != nil { return fail(ErrKind, "not a version constant: '%s'", r.constant) }
+    
+    // OpenGL and OpenGL ES versions are never equal, and never less or greater than each other
+    var ok = (r.symbol == "!=")
+    if result.ES == v.ES { ok = r.operationi(result.compare(v), 0) }
+    
+    if ok { return nil }
+    return fail(ErrRequirement, "%s is %s but must be %s %s", field, result, r.symbol, r.constant)
+}
+
+// ===[ requirementProfile ]==================================================================[ requirementProfile ]===
+
+type requirementProfile struct {
+    profile string
+}
+
+func (r requirementProfile) evalBool(field string, result bool) error {
+    return fail(ErrKind, "profile does not apply to bool field %s", field)
+}
+
+func (r requirementProfile) evalInt(field string, result int) error {
+    return fail(ErrKind, "profile does not apply to integer field %s", field)
+}
+
+func (r requirementProfile) evalFloat(field string, result float32) error {
+    return fail(ErrKind, "profile does not apply to float field %s", field)
+}
+
+func (r requirementProfile) evalString(field string, result string) error {
+    return fail(ErrKind, "profile does not apply to string field %s", field)
+}
+
+func (r requirementProfile) evalVersion(field string, result Version) error {
+    if result.Profile == r.profile { return nil }
+    return fail(ErrRequirement, "%s is %s but must have the %s profile", field, result, r.profile)
 }
 
 // ===[ commandValue ]==============================================================================[ commandValue ]===
@@ -95,28 +176,28 @@ type commandValue struct {
     value string
 }
 
-func (c commandValue) evalBool(_ *Binding, _ Extensions) bool {
+func (c commandValue) evalBool(_ *Binding, _ Extensions) (bool, error) {
     switch c.value {
-        case "true":  return true
-        case "false": return false
-        default: panic(fmt.Sprintf("not a boolean: '%s'", c.value))
+        case "true":  return true, nil
+        case "false": return false, nil
+        default: return false, fail(ErrKind, "not a boolean: '%s'", c.value)
     }
 }
 
-func (c commandValue) evalInt(_ *Binding, _ Extensions) int {
-    var result, err = strconv.ParseInt(c.value, 10, 32)
-    if err != nil { panic(fmt.Sprintf("not an integer: '%s'", c.value)) }
-    return int(result)
+func (c commandValue) evalInt(_ *Binding, _ Extensions) (int, error) {
+    var result, err = strconv.ParseInt(c.value, 10, strconv.IntSize)
+    if err != nil { return 0, fail(ErrKind, "not an integer: '%s'", c.value) }
+    return int(result), nil
 }
 
-func (c commandValue) evalFloat(_ *Binding, _ Extensions) float32 {
+func (c commandValue) evalFloat(_ *Binding, _ Extensions) (float32, error) {
     var result, err = strconv.ParseFloat(c.value, 32)
-    if err != nil { panic(fmt.Sprintf("not a float: '%s'", c.value)) }
-    return float32(result)
+    if err != nil { return 0, fail(ErrKind, "not a float: '%s'", c.value) }
+    return float32(result), nil
 }
 
-func (c commandValue) evalString(_ *Binding, _ Extensions) string {
-    return c.value
+func (c commandValue) evalString(_ *Binding, _ Extensions) (string, error) {
+    return c.value, nil
 }
 
 func (c commandValue) hasBoolRepresentation() bool {
@@ -128,7 +209,7 @@ func (c commandValue) hasBoolRepresentation() bool {
 }
 
 func (c commandValue) hasIntRepresentation() bool {
-    var _, err = strconv.ParseInt(c.value, 10, 32)
+    var _, err = strconv.ParseInt(c.value, 10, strconv.IntSize)
     return err == nil
 }
 
@@ -150,20 +231,26 @@ type commandBinaryBoolean struct {
     operation func(bool, bool) bool
 }
 
-func (c commandBinaryBoolean) evalBool(b *Binding, e Extensions) bool {
-    return c.operation(c.a.evalBool(b, e), c.b.evalBool(b, e))
+func (c commandBinaryBoolean) evalBool(b *Binding, e Extensions) (bool, error) {
+    var x, xerr = c.a.evalBool(b, e)
+    if xerr != nil { return false, xerr }
+    
+    var y, yerr = c.b.evalBool(b, e)
+    if yerr != nil { return false, yerr }
+    
+    return c.operation(x, y), nil
 }
 
-func (c commandBinaryBoolean) evalInt(b *Binding, _ Extensions) int {
-    panic("not an integer")
+func (c commandBinaryBoolean) evalInt(b *Binding, _ Extensions) (int, error) {
+    return 0, errNotInt
 }
 
-func (c commandBinaryBoolean) evalFloat(b *Binding, _ Extensions) float32 {
-    panic("not a float")
+func (c commandBinaryBoolean) evalFloat(b *Binding, _ Extensions) (float32, error) {
+    return 0, errNotFloat
 }
 
-func (c commandBinaryBoolean) evalString(_ *Binding, _ Extensions) string {
-    panic("not a string")
+func (c commandBinaryBoolean) evalString(_ *Binding, _ Extensions) (string, error) {
+    return "", errNotString
 }
 
 func (c commandBinaryBoolean) hasBoolRepresentation() bool {
@@ -188,20 +275,21 @@ type commandNot struct {
     inner command
 }
 
-func (c commandNot) evalBool(b *Binding, e Extensions) bool {
-    return !c.inner.evalBool(b, e)
+func (c commandNot) evalBool(b *Binding, e Extensions) (bool, error) {
+    var result, err = c.inner.evalBool(b, e)
+    return !result, err
 }
 
-func (c commandNot) evalInt(b *Binding, _ Extensions) int {
-    panic("not an integer")
+func (c commandNot) evalInt(b *Binding, _ Extensions) (int, error) {
+    return 0, errNotInt
 }
 
-func (c commandNot) evalFloat(b *Binding, _ Extensions) float32 {
-    panic("not a float")
+func (c commandNot) evalFloat(b *Binding, _ Extensions) (float32, error) {
+    return 0, errNotFloat
 }
 
-func (c commandNot) evalString(b *Binding, _ Extensions) string {
-    panic("not a string")
+func (c commandNot) evalString(b *Binding, _ Extensions) (string, error) {
+    return "", errNotString
 }
 
 func (c commandNot) hasBoolRepresentation() bool {
@@ -230,32 +318,59 @@ type commandCompare struct {
     operations func(string,  string)  bool
 }
 
-func (c commandCompare) evalBool(b *Binding, e Extensions) bool {
-    if c.a.hasFloatRepresentation() && c.b.hasFloatRepresentation() {
-        return c.operationf(c.a.evalFloat(b, e), c.b.evalFloat(b, e))
+func (c commandCompare) evalBool(b *Binding, e Extensions) (bool, error) {
+    if isVersionCommand(c.a) || isVersionCommand(c.b) {
+        return c.evalVersions(b, e)
+    } else if c.a.hasFloatRepresentation() && c.b.hasFloatRepresentation() {
+        var x, xerr = c.a.evalFloat(b, e)
+        if xerr != nil { return false, xerr }
+        var y, yerr = c.b.evalFloat(b, e)
+        if yerr != nil { return false, yerr }
+        return c.operationf(x, y), nil
     } else if c.a.hasIntRepresentation() && c.b.hasIntRepresentation() {
-        return c.operationi(c.a.evalInt(b, e), c.b.evalInt(b, e))
+        var x, xerr = c.a.evalInt(b, e)
+        if xerr != nil { return false, xerr }
+        var y, yerr = c.b.evalInt(b, e)
+        if yerr != nil { return false, yerr }
+        return c.operationi(x, y), nil
     } else if c.a.hasStringRepresentation() && c.b.hasStringRepresentation() {
-        if c.operations != nil {
-            return c.operations(c.a.evalString(b, e), c.b.evalString(b, e))
-        } else {
-            panic(fmt.Sprintf("string operation not defined for this comparison"))
+        if c.operations == nil {
+            return false, fail(ErrKind, "string operation not defined for this comparison")
         }
+        var x, xerr = c.a.evalString(b, e)
+        if xerr != nil { return false, xerr }
+        var y, yerr = c.b.evalString(b, e)
+        if yerr != nil { return false, yerr }
+        return c.operations(x, y), nil
     } else {
-        panic(fmt.Sprintf("cannot compare mismatched types (%+v and %+v)", c.a, c.b))
+        return false, fail(ErrKind, "cannot compare mismatched types (%+v and %+v)", c.a, c.b)
     }
 }
 
-func (c commandCompare) evalInt(b *Binding, e Extensions) int {
-    panic("not an integer")
+// evalVersions compares two versions, where one may be a version literal such as 4.3 or ES3.1
+func (c commandCompare) evalVersions(b *Binding, e Extensions) (bool, error) {
+    var x, xerr = evalVersion(c.a, b, e)
+    if xerr != nil { return false, xerr }
+    var y, yerr = evalVersion(c.b, b, e)
+    if yerr != nil { return false, yerr }
+    
+    // OpenGL and OpenGL ES versions are never equal, and never less or greater than each other, so only an operation
+    // that is true both ways round (i.e. neq) is true
+    if x.ES != y.ES { return c.operationi(0, 1) && c.operationi(1, 0), nil }
+    
+    return c.operationi(x.compare(y), 0), nil
 }
 
-func (c commandCompare) evalFloat(b *Binding, e Extensions) float32 {
-    panic("not a float")
+func (c commandCompare) evalInt(b *Binding, e Extensions) (int, error) {
+    return 0, errNotInt
 }
 
-func (c commandCompare) evalString(b *Binding, e Extensions) string {
-    panic("not a string")
+func (c commandCompare) evalFloat(b *Binding, e Extensions) (float32, error) {
+    return 0, errNotFloat
+}
+
+func (c commandCompare) evalString(b *Binding, e Extensions) (string, error) {
+    return "", errNotString
 }
 
 func (c commandCompare) hasBoolRepresentation() bool {
@@ -274,26 +389,42 @@ func (c commandCompare) hasStringRepresentation() bool {
     return c.a.hasStringRepresentation() && c.b.hasStringRepresentation()
 }
 
+// isVersionCommand returns true if c returns a Version
+func isVersionCommand(c command) bool {
+    if ic, ok := c.(commandIf); ok { return isVersionCommand(ic.implication) || isVersionCommand(ic.otherwise) }
+    var _, ok = c.(versionCommand)
+    return ok
+}
+
+// evalVersion evaluates a command that returns a Version, or parses the result of any other command as a version
+func evalVersion(c command, b *Binding, e Extensions) (Version, error) {
+    if vc, ok := c.(versionCommand); ok { return vc.evalVersion(b, e) }
+    
+    var s, err = c.evalString(b, e)
+    if err != nil { return Version{}, err }
+    return ParseVersion(s)
+}
+
 // ===[ commandExt ]==================================================================================[ commandExt ]===
 
 type commandExt struct {
     name string
 }
 
-func (c commandExt) evalBool(b *Binding, e Extensions) bool {
-    return e.Contains(c.name)
+func (c commandExt) evalBool(b *Binding, e Extensions) (bool, error) {
+    return e.Contains(c.name), nil
 }
 
-func (c commandExt) evalInt(b *Binding, e Extensions) int {
-    panic("not an integer")
+func (c commandExt) evalInt(b *Binding, e Extensions) (int, error) {
+    return 0, errNotInt
 }
 
-func (c commandExt) evalFloat(b *Binding, e Extensions) float32 {
-    panic("not a float")
+func (c commandExt) evalFloat(b *Binding, e Extensions) (float32, error) {
+    return 0, errNotFloat
 }
 
-func (c commandExt) evalString(b *Binding, e Extensions) string {
-    panic("not a string")
+func (c commandExt) evalString(b *Binding, e Extensions) (string, error) {
+    return "", errNotString
 }
 
 func (c commandExt) hasBoolRepresentation() bool {
@@ -318,24 +449,46 @@ type commandGetIntegerv struct {
     name string
 }
 
-func (c commandGetIntegerv) evalBool(b *Binding, e Extensions) bool {
-    panic("not a bool")
+func (c commandGetIntegerv) evalBool(b *Binding, e Extensions) (bool, error) {
+    return false, errNotBool
+}
+
+func (c commandGetIntegerv) evalInt(b *Binding, e Extensions) (int, error) {
+    var results, err = c.evalInts(b, e, 1)
+    if err != nil { return 0, err }
+    return results[0], nil
+}
+
+func (c commandGetIntegerv) evalInts(b *Binding, e Extensions, n int) ([]int, error) {
+    var id, err = lookupConstant(c.name)
+    if err != nil { return nil, err }
+    if (b == nil) || (b.GetIntegerv == nil) { return nil, fail(ErrNoContext, "binding has no GetIntegerv") }
+    
+    // always size the buffer for every result, because OpenGL writes every result even if we only want some
+    var count int
+    count, err = resultCount(b, c.name)
+    if err != nil { return nil, err }
+    if n == 0 { n = count }
+    if n > count { return nil, fail(ErrKind, "%s has %d values, not %d", c.name, count, n) }
+    
+    var buf = make([]int32, bufferSize(n, count))
+    b.GetIntegerv(id, &buf[0])
+    
+    var results = make([]int, n)
+    for i := range results { results[i] = int(buf[i]) }
+    return results, nil
 }
 
-func (c commandGetIntegerv) evalInt(b *Binding, e Extensions) int {
-    var result int32
-    var id, exists = glconstants[c.name]
-    if !exists { return 0 }
-    b.GetIntegerv(id, &result)
-    return int(result)
+func (c commandGetIntegerv) evalFloats(b *Binding, e Extensions, n int) ([]float32, error) {
+    return nil, errNotFloat
 }
 
-func (c commandGetIntegerv) evalFloat(b *Binding, e Extensions) float32 {
-    panic("not a float")
+func (c commandGetIntegerv) evalFloat(b *Binding, e Extensions) (float32, error) {
+    return 0, errNotFloat
 }
 
-func (c commandGetIntegerv) evalString(b *Binding, e Extensions) string {
-    panic("not a string")
+func (c commandGetIntegerv) evalString(b *Binding, e Extensions) (string, error) {
+    return "", errNotString
 }
 
 func (c commandGetIntegerv) hasBoolRepresentation() bool {
@@ -360,24 +513,43 @@ type commandGetFloatv struct {
     name string
 }
 
-func (c commandGetFloatv) evalBool(b *Binding, e Extensions) bool {
-    panic("not a bool")
+func (c commandGetFloatv) evalBool(b *Binding, e Extensions) (bool, error) {
+    return false, errNotBool
 }
 
-func (c commandGetFloatv) evalInt(b *Binding, e Extensions) int {
-    panic("not an int")
+func (c commandGetFloatv) evalInt(b *Binding, e Extensions) (int, error) {
+    return 0, errNotInt
 }
 
-func (c commandGetFloatv) evalFloat(b *Binding, e Extensions) float32 {
-    var result float32
-    var id, exists = glconstants[c.name]
-    if !exists { return 0.0 }
-    b.GetFloatv(id, &result)
-    return result
+func (c commandGetFloatv) evalFloat(b *Binding, e Extensions) (float32, error) {
+    var results, err = c.evalFloats(b, e, 1)
+    if err != nil { return 0, err }
+    return results[0], nil
 }
 
-func (c commandGetFloatv) evalString(b *Binding, e Extensions) string {
-    panic("not a string")
+func (c commandGetFloatv) evalInts(b *Binding, e Extensions, n int) ([]int, error) {
+    return nil, errNotInt
+}
+
+func (c commandGetFloatv) evalFloats(b *Binding, e Extensions, n int) ([]float32, error) {
+    var id, err = lookupConstant(c.name)
+    if err != nil { return nil, err }
+    if (b == nil) || (b.GetFloatv == nil) { return nil, fail(ErrNoContext, "binding has no GetFloatv") }
+    
+    // always size the buffer for every result, because OpenGL writes every result even if we only want some
+    var count int
+    count, err = resultCount(b, c.name)
+    if err != nil { return nil, err }
+    if n == 0 { n = count }
+    if n > count { return nil, fail(ErrKind, "%s has %d values, not %d", c.name, count, n) }
+    
+    var buf = make([]float32, bufferSize(n, count))
+    b.GetFloatv(id, &buf[0])
+    return buf[0:n], nil
+}
+
+func (c commandGetFloatv) evalString(b *Binding, e Extensions) (string, error) {
+    return "", errNotString
 }
 
 func (c commandGetFloatv) hasBoolRepresentation() bool {
@@ -396,28 +568,159 @@ func (c commandGetFloatv) hasStringRepresentation() bool {
     return false
 }
 
+// ===[ commandGetIntegeriv ]================================================================[ commandGetIntegeriV ]===
+
+// commandGetIntegeriv queries an indexed integer value. As an integer, it returns the value at index 0. As a vector,
+// it returns the value at each index in turn.
+type commandGetIntegeriv struct {
+    name string
+}
+
+func (c commandGetIntegeriv) evalBool(b *Binding, e Extensions) (bool, error) {
+    return false, errNotBool
+}
+
+func (c commandGetIntegeriv) evalInt(b *Binding, e Extensions) (int, error) {
+    var results, err = c.evalInts(b, e, 1)
+    if err != nil { return 0, err }
+    return results[0], nil
+}
+
+func (c commandGetIntegeriv) evalInts(b *Binding, e Extensions, n int) ([]int, error) {
+    var id, err = lookupConstant(c.name)
+    if err != nil { return nil, err }
+    if (b == nil) || (b.GetIntegeri_v == nil) { return nil, fail(ErrNoContext, "binding has no GetIntegeri_v") }
+    var count int
+    count, err = indexCount(b, c.name)
+    if err != nil { return nil, err }
+    if n == 0 { n = count }
+    if n > count { return nil, fail(ErrKind, "%s has %d indexes, not %d", c.name, count, n) }
+    
+    var buf = make([]int32, maxResults)
+    var results = make([]int, n)
+    for i := range results {
+        b.GetIntegeri_v(id, uint32(i), &buf[0])
+        results[i] = int(buf[0])
+    }
+    return results, nil
+}
+
+func (c commandGetIntegeriv) evalFloat(b *Binding, e Extensions) (float32, error) {
+    return 0, errNotFloat
+}
+
+func (c commandGetIntegeriv) evalFloats(b *Binding, e Extensions, n int) ([]float32, error) {
+    return nil, errNotFloat
+}
+
+func (c commandGetIntegeriv) evalString(b *Binding, e Extensions) (string, error) {
+    return "", errNotString
+}
+
+func (c commandGetIntegeriv) hasBoolRepresentation() bool {
+    return false
+}
+
+func (c commandGetIntegeriv) hasIntRepresentation() bool {
+    return true
+}
+
+func (c commandGetIntegeriv) hasFloatRepresentation() bool {
+    return false
+}
+
+func (c commandGetIntegeriv) hasStringRepresentation() bool {
+    return false
+}
+
+// ===[ commandGetFloativ ]====================================================================[ commandGetFloatiV ]===
+
+// commandGetFloativ queries an indexed float value. As a float, it returns the value at index 0. As a vector, it
+// returns the value at each index in turn.
+type commandGetFloativ struct {
+    name string
+}
+
+func (c commandGetFloativ) evalBool(b *Binding, e Extensions) (bool, error) {
+    return false, errNotBool
+}
+
+func (c commandGetFloativ) evalInt(b *Binding, e Extensions) (int, error) {
+    return 0, errNotInt
+}
+
+func (c commandGetFloativ) evalInts(b *Binding, e Extensions, n int) ([]int, error) {
+    return nil, errNotInt
+}
+
+func (c commandGetFloativ) evalFloat(b *Binding, e Extensions) (float32, error) {
+    var results, err = c.evalFloats(b, e, 1)
+    if err != nil { return 0, err }
+    return results[0], nil
+}
+
+func (c commandGetFloativ) evalFloats(b *Binding, e Extensions, n int) ([]float32, error) {
+    var id, err = lookupConstant(c.name)
+    if err != nil { return nil, err }
+    if (b == nil) || (b.GetFloati_v == nil) { return nil, fail(ErrNoContext, "binding has no GetFloati_v") }
+    var count int
+    count, err = indexCount(b, c.name)
+    if err != nil { return nil, err }
+    if n == 0 { n = count }
+    if n > count { return nil, fail(ErrKind, "%s has %d indexes, not %d", c.name, count, n) }
+    
+    var buf = make([]float32, maxResults)
+    var results = make([]float32, n)
+    for i := range results {
+        b.GetFloati_v(id, uint32(i), &buf[0])
+        results[i] = buf[0]
+    }
+    return results, nil
+}
+
+func (c commandGetFloativ) evalString(b *Binding, e Extensions) (string, error) {
+    return "", errNotString
+}
+
+func (c commandGetFloativ) hasBoolRepresentation() bool {
+    return false
+}
+
+func (c commandGetFloativ) hasIntRepresentation() bool {
+    return false
+}
+
+func (c commandGetFloativ) hasFloatRepresentation() bool {
+    return true
+}
+
+func (c commandGetFloativ) hasStringRepresentation() bool {
+    return false
+}
+
 // ===[ commandGetString ]======================================================================[ commandGetString ]===
 
 type commandGetString struct {
     name string
 }
 
-func (c commandGetString) evalBool(b *Binding, e Extensions) bool {
-    panic("not a bool")
+func (c commandGetString) evalBool(b *Binding, e Extensions) (bool, error) {
+    return false, errNotBool
 }
 
-func (c commandGetString) evalInt(b *Binding, e Extensions) int {
-    panic("not an int")
+func (c commandGetString) evalInt(b *Binding, e Extensions) (int, error) {
+    return 0, errNotInt
 }
 
-func (c commandGetString) evalFloat(b *Binding, e Extensions) float32 {
-    panic("not a float")
+func (c commandGetString) evalFloat(b *Binding, e Extensions) (float32, error) {
+    return 0, errNotFloat
 }
 
-func (c commandGetString) evalString(b *Binding, e Extensions) string {
-    var id, exists = glconstants[c.name]
-    if !exists { return "" }
-    return b.GetString(id)
+func (c commandGetString) evalString(b *Binding, e Extensions) (string, error) {
+    var id, err = lookupConstant(c.name)
+    if err != nil { return "", err }
+    if (b == nil) || (b.GetString == nil) { return "", fail(ErrNoContext, "binding has no GetString") }
+    return b.GetString(id), nil
 }
 
 func (c commandGetString) hasBoolRepresentation() bool {
@@ -436,6 +739,71 @@ func (c commandGetString) hasStringRepresentation() bool {
     return true
 }
 
+// ===[ commandVersion ]==========================================================================[ commandVersion ]===
+
+// commandVersion parses the result of GetString for GL_VERSION or GL_SHADING_LANGUAGE_VERSION as a Version.
+type commandVersion struct {
+    name string
+}
+
+func (c commandVersion) evalBool(b *Binding, e Extensions) (bool, error) {
+    return false, errNotBool
+}
+
+func (c commandVersion) evalInt(b *Binding, e Extensions) (int, error) {
+    return 0, errNotInt
+}
+
+func (c commandVersion) evalFloat(b *Binding, e Extensions) (float32, error) {
+    return 0, errNotFloat
+}
+
+func (c commandVersion) evalString(b *Binding, e Extensions) (string, error) {
+    var v, err = c.evalVersion(b, e)
+    if err != nil { return "", err }
+    return v.String(), nil
+}
+
+func (c commandVersion) evalVersion(b *Binding, e Extensions) (Version, error) {
+    var s, err = commandGetString{c.name}.evalString(b, e)
+    if err != nil { return Version{}, err }
+    
+    var v Version
+    v, err = ParseVersion(s)
+    if err != nil { return Version{}, err }
+    
+    // desktop OpenGL 3.2+ can say which profile it is, if GL_VERSION doesn't
+    var hasProfileMask = (v.Major > 3) || ((v.Major == 3) && (v.Minor >= 2))
+    if (c.name == "GL_VERSION") && !v.ES && (v.Profile == "") && hasProfileMask {
+        var mask, err = commandGetIntegerv{"GL_CONTEXT_PROFILE_MASK"}.evalInt(b, e)
+        if err != nil { return Version{}, err }
+        
+        if mask & int(glconstants["GL_CONTEXT_CORE_PROFILE_BIT"]) != 0 {
+            v.Profile = "core"
+        } else if mask & int(glconstants["GL_CONTEXT_COMPATIBILITY_PROFILE_BIT"]) != 0 {
+            v.Profile = "compatibility"
+        }
+    }
+    
+    return v, nil
+}
+
+func (c commandVersion) hasBoolRepresentation() bool {
+    return false
+}
+
+func (c commandVersion) hasIntRepresentation() bool {
+    return false
+}
+
+func (c commandVersion) hasFloatRepresentation() bool {
+    return false
+}
+
+func (c commandVersion) hasStringRepresentation() bool {
+    return true
+}
+
 // ===[ commandIf ]====================================================================================[ commandIf ]===
 
 type commandIf struct {
@@ -444,40 +812,55 @@ type commandIf struct {
     otherwise command
 }
 
-func (c commandIf) evalBool(b *Binding, e Extensions) bool {
-    if !c.hasBoolRepresentation() { panic(fmt.Sprintf("both clauses of %+v must have a bool representation", c)) }
-    if c.clause.evalBool(b, e) {
-        return c.implication.evalBool(b, e)
-    } else {
-        return c.otherwise.evalBool(b, e)
+// branch evaluates the clause and returns the command to evaluate next
+func (c commandIf) branch(b *Binding, e Extensions) (command, error) {
+    var clause, err = c.clause.evalBool(b, e)
+    if err != nil { return nil, err }
+    if clause { return c.implication, nil }
+    return c.otherwise, nil
+}
+
+func (c commandIf) evalBool(b *Binding, e Extensions) (bool, error) {
+    if !c.hasBoolRepresentation() {
+        return false, fail(ErrKind, "both clauses of %+v must have a bool representation", c)
     }
+    var next, err = c.branch(b, e)
+    if err != nil { return false, err }
+    return next.evalBool(b, e)
 }
 
-func (c commandIf) evalInt(b *Binding, e Extensions) int {
-    if !c.hasIntRepresentation() { panic(fmt.Sprintf("both clauses of %+v must have an int representation", c)) }
-    if c.clause.evalBool(b, e) {
-        return c.implication.evalInt(b, e)
-    } else {
-        return c.otherwise.evalInt(b, e)
+func (c commandIf) evalInt(b *Binding, e Extensions) (int, error) {
+    if !c.hasIntRepresentation() {
+        return 0, fail(ErrKind, "both clauses of %+v must have an int representation", c)
     }
+    var next, err = c.branch(b, e)
+    if err != nil { return 0, err }
+    return next.evalInt(b, e)
 }
 
-func (c commandIf) evalFloat(b *Binding, e Extensions) float32 {
-    if !c.hasFloatRepresentation() { panic(fmt.Sprintf("both clauses of %+v must have an int representation", c)) }
-    if c.clause.evalBool(b, e) {
-        return c.implication.evalFloat(b, e)
-    } else {
-        return c.otherwise.evalFloat(b, e)
+func (c commandIf) evalFloat(b *Binding, e Extensions) (float32, error) {
+    if !c.hasFloatRepresentation() {
+        return 0, fail(ErrKind, "both clauses of %+v must have a float representation", c)
     }
+    var next, err = c.branch(b, e)
+    if err != nil { return 0, err }
+    return next.evalFloat(b, e)
 }
 
-func (c commandIf) evalString(b *Binding, e Extensions) string {
-    if !c.hasStringRepresentation() { panic(fmt.Sprintf("both clauses of %+v must have a string representation", c)) }
-    if c.clause.evalBool(b, e) {
-        return c.implication.evalString(b, e)
-    } else {
-        return c.otherwise.evalString(b, e)
+func (c commandIf) evalString(b *Binding, e Extensions) (string, error) {
+    if !c.hasStringRepresentation() {
+        return "", fail(ErrKind, "both clauses of %+v must have a string representation", c)
     }
+    var next, err = c.branch(b, e)
+    if err != nil { return "", err }
+    return next.evalString(b, e)
+}
+
+// evalVersion passes a Version through without turning it into a string, so nothing (such as the vendor) is lost
+func (c commandIf) evalVersion(b *Binding, e Extensions) (Version, error) {
+    var next, err = c.branch(b, e)
+    if err != nil { return Version{}, err }
+    return evalVersion(next, b, e)
 }
 
 func (c commandIf) hasBoolRepresentation() bool {
@@ -1,9 +1,71 @@
 package glcaps
 
 import (
+    "errors"
+    "reflect"
+    "sync"
     "testing"
+    "unsafe"
+    
+    "tawesoft.co.uk/go/operator"
 )
 
+// evalBool evaluates a command that is expected to succeed
+func evalBool(t *testing.T, c command, e Extensions) bool {
+    t.Helper()
+    var result, err = c.evalBool(nil, e)
+    if err != nil { t.Fatalf("unexpected error: %v", err) }
+    return result
+}
+
+// testBinding returns a Binding for a fake OpenGL context with one extension
+func testBinding() *Binding {
+    // like OpenGL, write several values to a pointer to the first
+    var ints = func(data *int32, values ... int32) {
+        copy(int32s(data, len(values)), values)
+    }
+    var floats = func(data *float32, values ... float32) {
+        copy(float32s(data, len(values)), values)
+    }
+    
+    return &Binding{
+        GetIntegerv: func(name uint32, data *int32) {
+            switch name {
+                case glconstants["GL_NUM_EXTENSIONS"]:                 ints(data, 1)
+                case glconstants["GL_MAX_TEXTURE_SIZE"]:               ints(data, 4096)
+                case glconstants["GL_MAX_VIEWPORT_DIMS"]:              ints(data, 8192, 4096)
+                case glconstants["GL_MAX_VIEWPORTS"]:                  ints(data, 2)
+                case glconstants["GL_NUM_COMPRESSED_TEXTURE_FORMATS"]: ints(data, 3)
+                case glconstants["GL_COMPRESSED_TEXTURE_FORMATS"]:     ints(data, 10, 20, 30)
+                case glconstants["GL_CONTEXT_PROFILE_MASK"]:           ints(data, 1)
+            }
+        },
+        GetFloatv: func(name uint32, data *float32) {
+            switch name {
+                case glconstants["GL_ALIASED_LINE_WIDTH_RANGE"]: floats(data, 1.0, 7.5)
+            }
+        },
+        GetIntegeri_v: func(name uint32, index uint32, data *int32) {
+            switch name {
+                case glconstants["GL_MAX_COMPUTE_WORK_GROUP_COUNT"]: ints(data, 65535 - int32(index))
+            }
+        },
+        GetFloati_v: func(name uint32, index uint32, data *float32) {
+            switch name {
+                case glconstants["GL_VIEWPORT"]: floats(data, float32(index) * 100, 0, 640, 480)
+            }
+        },
+        GetString:  func(name uint32) string {
+            switch name {
+                case glconstants["GL_VERSION"]:                  return "4.6.0 NVIDIA 535.1"
+                case glconstants["GL_SHADING_LANGUAGE_VERSION"]: return "4.60 NVIDIA"
+                default:                                         return "Example Vendor"
+            }
+        },
+        GetStringi: func(name uint32, index uint32) string { return "GL_EXT_foo" },
+    }
+}
+
 func TestParseAtom1(t *testing.T) {
     var atom, index = parseAtom("a b c", 0)
     if atom != "a" { t.Errorf("unexpected result") }
@@ -40,7 +102,7 @@ func TestCommandExt(t *testing.T) {
     var cExt = command.(commandExt)
     if (cExt.name != "FOO") { t.Errorf("unexpected result: expected 'FOO' but got '%s'", cExt.name) }
     
-    var result = command.evalBool(nil, ext)
+    var result = evalBool(t, command, ext)
     if result != true { t.Errorf("unexpected result") }
 }
 
@@ -63,47 +125,47 @@ func TestEvalCommandAnd1(t *testing.T) {
     
     var command, _, err = parseCommand("and true true", 0)
     if err != nil { t.Failed() }
-    if !command.evalBool(nil, nil) { t.Errorf("unexpected result") }
+    if !evalBool(t, command, nil) { t.Errorf("unexpected result") }
 }
 
 func TestEvalCommandAnd2(t *testing.T) {
     
     var command, _, err = parseCommand("and true false", 0)
     if err != nil { t.Failed() }
-    if command.evalBool(nil, nil) { t.Errorf("unexpected result") }
+    if evalBool(t, command, nil) { t.Errorf("unexpected result") }
 }
 
 func TestEvalCommandOr1(t *testing.T) {
     
     var command, _, err = parseCommand("or true true", 0)
     if err != nil { t.Failed() }
-    if !command.evalBool(nil, nil) { t.Errorf("unexpected result") }
+    if !evalBool(t, command, nil) { t.Errorf("unexpected result") }
 }
 
 func TestEvalCommandCompoundBoolean1(t *testing.T) {
     
     var command, _, err = parseCommand("and or true false or false true", 0)
     if err != nil { t.Failed() }
-    if !command.evalBool(nil, nil) { t.Errorf("unexpected result") }
+    if !evalBool(t, command, nil) { t.Errorf("unexpected result") }
 }
 
 func TestEvalCommandCompoundBoolean2(t *testing.T) {
     
     var command, _, err = parseCommand("or and true true and false false", 0)
     if err != nil { t.Failed() }
-    if !command.evalBool(nil, nil) { t.Errorf("unexpected result") }
+    if !evalBool(t, command, nil) { t.Errorf("unexpected result") }
 }
 
 func TestEvalCommandExtraSpace(t *testing.T) {
     var command, _, err = parseCommand("and  true  true", 0)
     if err != nil { t.Errorf("unexpected result") }
-    if !command.evalBool(nil, nil) { t.Errorf("unexpected result") }
+    if !evalBool(t, command, nil) { t.Errorf("unexpected result") }
 }
 
 func TestEvalCommandLeadingSpace(t *testing.T) {
     var command, _, err = parseCommand("  and true true", 0)
     if err != nil { t.Errorf("unexpected result") }
-    if !command.evalBool(nil, nil) { t.Errorf("unexpected result") }
+    if !evalBool(t, command, nil) { t.Errorf("unexpected result") }
 }
 
 func TestParseCommandTrailingSpace(t *testing.T) {
@@ -144,15 +206,379 @@ func TestParseTagExtraSpace(t *testing.T) {
 func TestEvalCommandLt1(t *testing.T) {
     var command, _, err = parseCommand("lt 1 2", 0)
     if err != nil { t.Failed() }
-    if !command.evalBool(nil, nil) { t.Errorf("unexpected result") }
+    if !evalBool(t, command, nil) { t.Errorf("unexpected result") }
 }
 
 func TestEvalCommandLt2(t *testing.T) {
     var command, _, err = parseCommand("lt 2.5 1.0", 0)
     if err != nil { t.Failed() }
-    if command.evalBool(nil, nil) { t.Errorf("unexpected result") }
+    if evalBool(t, command, nil) { t.Errorf("unexpected result") }
+}
+
+func TestParseErrors(t *testing.T) {
+    type test struct {
+        target interface{}
+        binding *Binding
+        expected error
+    }
+    
+    var tests = []test{
+        {&struct{ X bool   `glcaps:"and true"`                        }{}, testBinding(), ErrSyntax},
+        {&struct{ X bool   `glcaps:"ext GL_EXT_foo; nonsense"`        }{}, testBinding(), ErrSyntax},
+        {&struct{ X bool   `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"` }{}, testBinding(), ErrKind},
+        {&struct{ X int    `glcaps:"ext GL_EXT_foo"`                  }{}, testBinding(), ErrKind},
+        {&struct{ X int    `glcaps:"123; required"`                   }{}, testBinding(), ErrKind},
+        {&struct{ X int    `glcaps:"abc"`                             }{}, testBinding(), ErrKind},
+        {&struct{ X string `glcaps:"GetString GL_VENDOR; lt 100"`     }{}, testBinding(), ErrKind},
+        {&struct{ X int    `glcaps:"GetIntegerv GL_MAX_TYPO"`         }{}, testBinding(), ErrUnknownConstant},
+        {&struct{ X int    `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"` }{}, &Binding{},   ErrNoContext},
+        {&struct{ X int    `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"` }{}, nil,          ErrNoContext},
+        {&struct{ X int    `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE; gte 8192"` }{}, testBinding(), ErrRequirement},
+        {&struct{ X bool   `glcaps:"ext GL_EXT_bar; required"`        }{}, testBinding(), ErrRequirement},
+        {&struct{ X uint32 `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE; gte 4000000000"` }{}, testBinding(),
+            ErrRequirement},
+        {&struct{ X [2]int `glcaps:"GetFloatv GL_ALIASED_LINE_WIDTH_RANGE"` }{}, testBinding(), ErrKind},
+        {&struct{ X []int  `glcaps:"ext GL_EXT_foo"`                  }{}, testBinding(), ErrKind},
+        {&struct{ X []bool `glcaps:"GetIntegerv GL_MAX_VIEWPORT_DIMS"` }{}, testBinding(), ErrKind},
+        {&struct{ X [3]int `glcaps:"GetIntegerv GL_MAX_VIEWPORT_DIMS"` }{}, testBinding(), ErrKind},
+        {&struct{ X [2]int `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"` }{}, testBinding(), ErrKind},
+        {&struct{ X [3]float32 `glcaps:"GetFloatv GL_ALIASED_LINE_WIDTH_RANGE"` }{}, testBinding(), ErrKind},
+        {&struct{ X [4]int `glcaps:"GetIntegeri_v GL_MAX_COMPUTE_WORK_GROUP_COUNT"` }{}, testBinding(), ErrKind},
+        {&struct{ X []int  `glcaps:"GetIntegeri_v GL_MAX_TYPO"`       }{}, testBinding(), ErrUnknownConstant},
+        {&struct{ X []int  `glcaps:"GetIntegeri_v GL_MAX_COMPUTE_WORK_GROUP_COUNT"` }{}, &Binding{
+            GetIntegerv: testBinding().GetIntegerv,
+            GetStringi:  testBinding().GetStringi,
+        }, ErrNoContext},
+        {&struct{ X int8   `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"` }{}, testBinding(), operator.ErrorOverflow},
+        {&struct{ X uint   `glcaps:"-1"`                              }{}, testBinding(), operator.ErrorOverflow},
+        {&struct{ X []int8 `glcaps:"GetIntegerv GL_MAX_VIEWPORT_DIMS"` }{}, testBinding(), operator.ErrorOverflow},
+        {&struct{ X complex64 `glcaps:"1"`                            }{}, testBinding(), ErrKind},
+        {&struct{ X Version `glcaps:"version GL_VERSION; gte 4.7"`   }{}, testBinding(), ErrRequirement},
+        {&struct{ X Version `glcaps:"version GL_VERSION; gte ES3.0"` }{}, testBinding(), ErrRequirement},
+        {&struct{ X Version `glcaps:"version GL_VERSION; profile es"` }{}, testBinding(), ErrRequirement},
+        {&struct{ X Version `glcaps:"version GL_VERSION; profile x"` }{}, testBinding(), ErrSyntax},
+        {&struct{ X Version `glcaps:"version GL_VERSION; gte abc"`   }{}, testBinding(), ErrKind},
+        {&struct{ X Version `glcaps:"version GL_VENDOR"`             }{}, testBinding(), ErrSyntax},
+        {&struct{ X Version `glcaps:"ext GL_EXT_foo"`                }{}, testBinding(), ErrKind},
+        {&struct{ X int     `glcaps:"version GL_VERSION"`            }{}, testBinding(), ErrKind},
+        {&struct{ X map[string]int `glcaps:"1"`                       }{}, testBinding(), ErrKind},
+        {&struct{ x int    `glcaps:"1"`                               }{}, testBinding(), ErrKind},
+        {&struct{ mu sync.Mutex; X bool `glcaps:"ext GL_EXT_bar; required"` }{}, testBinding(), ErrRequirement},
+        {struct{}{}, testBinding(), ErrKind},
+        {nil,        testBinding(), ErrKind},
+    }
+    
+    for i, test := range tests {
+        var _, errs = Parse(test.binding, test.target)
+        if len(errs) != 1 {
+            t.Errorf("Test %d: got %d errors (%v) but wanted 1", i, len(errs), errs)
+        } else if !errors.Is(errs[0], test.expected) {
+            t.Errorf("Test %d: got %v but wanted %v", i, errs[0].Err, test.expected)
+        }
+    }
+}
+
+func TestParseContinuesAfterError(t *testing.T) {
+    var target = struct{
+        Typo     int  `glcaps:"GetIntegerv GL_MAX_TYPO"`
+        MaxSize  int  `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"`
+        HaveFoo  bool `glcaps:"ext GL_EXT_foo"`
+    }{Typo: -1}
+    
+    var extensions, errs = Parse(testBinding(), &target)
+    if len(errs) != 1 || errs[0].Field != "Typo" { t.Errorf("unexpected errors: %v", errs) }
+    if !extensions.Contains("GL_EXT_foo")        { t.Errorf("unexpected extensions: %v", extensions) }
+    if target.Typo != -1                          { t.Errorf("unexpected result: %d", target.Typo) }
+    if target.MaxSize != 4096                     { t.Errorf("unexpected result: %d", target.MaxSize) }
+    if !target.HaveFoo                            { t.Errorf("unexpected result") }
+}
+
+func TestParseNumbers(t *testing.T) {
+    var target struct{
+        I16   int16   `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"`
+        I32   int32   `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"`
+        I64   int64   `glcaps:"-7"`
+        U     uint    `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"`
+        U8    uint8   `glcaps:"255"`
+        U32   uint32  `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE; gte 1024 lt 4000000000"`
+        I64r  int64   `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE; neq -9000000000 gt -9000000000"`
+        F64   float64 `glcaps:"1.5"`
+        Other []bool  // no tag, so ignored
+    }
+    
+    var _, errs = Parse(testBinding(), &target)
+    if len(errs) != 0 { t.Fatalf("unexpected errors: %v", errs) }
+    
+    type test struct {
+        result interface{}
+        expected interface{}
+    }
+    
+    var tests = []test{
+        {target.I16, int16(4096)},
+        {target.I32, int32(4096)},
+        {target.I64, int64(-7)},
+        {target.U,   uint(4096)},
+        {target.U8,  uint8(255)},
+        {target.U32, uint32(4096)},
+        {target.F64, 1.5},
+    }
+    
+    for i, test := range tests {
+        if test.result != test.expected {
+            t.Errorf("Test %d: got %v but wanted %v", i, test.result, test.expected)
+        }
+    }
+}
+
+func TestParseOverflowUnchanged(t *testing.T) {
+    var target = struct{
+        X uint8 `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"`
+    }{X: 7}
+    
+    var _, errs = Parse(testBinding(), &target)
+    if len(errs) != 1 { t.Fatalf("unexpected errors: %v", errs) }
+    
+    var expected = "X is 4096, which overflows uint8"
+    if errs[0].Message != expected { t.Errorf("got %q but wanted %q", errs[0].Message, expected) }
+    if target.X != 7 { t.Errorf("unexpected result: %d", target.X) }
+}
+
+func TestParseVersion(t *testing.T) {
+    type test struct {
+        input string
+        expected Version
+    }
+    
+    var tests = []test{
+        {"4.6.0 NVIDIA 535.1",                  Version{4, 6, false, "", "NVIDIA 535.1"}},
+        {"3.3 (Core Profile) Mesa 20.0.8",      Version{3, 3, false, "core", "Mesa 20.0.8"}},
+        {"4.6 (Compatibility Profile) Mesa 23", Version{4, 6, false, "compatibility", "Mesa 23"}},
+        {"2.1",                                 Version{2, 1, false, "", ""}},
+        {"4.1 Metal - 83.1",                    Version{4, 1, false, "", "Metal - 83.1"}},
+        {"OpenGL ES 3.2 Mesa 20.0.8",           Version{3, 2, true, "es", "Mesa 20.0.8"}},
+        {"OpenGL ES-CM 1.1",                    Version{1, 1, true, "es", ""}},
+        {"4.60 NVIDIA",                         Version{4, 6, false, "", "NVIDIA"}},
+        {"1.50",                                Version{1, 5, false, "", ""}},
+        {"OpenGL ES GLSL ES 3.20",              Version{3, 2, true, "es", ""}},
+        {"OpenGL ES GLSL ES 1.00",              Version{1, 0, true, "es", ""}},
+        {"ES3.1",                               Version{3, 1, true, "es", ""}},
+        {"4.6 core",                            Version{4, 6, false, "core", ""}},
+        {"3.2 compatibility",                   Version{3, 2, false, "compatibility", ""}},
+    }
+    
+    for i, test := range tests {
+        var result, err = ParseVersion(test.input)
+        if err != nil {
+            t.Errorf("Test %d: unexpected error %v", i, err)
+        } else if result != test.expected {
+            t.Errorf("Test %d: got %+v but wanted %+v", i, result, test.expected)
+        }
+        
+        // String gives the short form, which parses to the same version without the vendor
+        var short = test.expected
+        short.Vendor = ""
+        if result, err = ParseVersion(short.String()); (err != nil) || (result != short) {
+            t.Errorf("Test %d: %q parsed as %+v, %v but wanted %+v", i, short.String(), result, err, short)
+        }
+    }
+    
+    for _, input := range []string{"", "NVIDIA", "4", "4.x", "OpenGL ES"} {
+        var _, err = ParseVersion(input)
+        if !errors.Is(err, ErrSyntax) { t.Errorf("ParseVersion(%q): got %v but wanted %v", input, err, ErrSyntax) }
+    }
+}
+
+func TestParseVersionFields(t *testing.T) {
+    var es = testBinding()
+    es.GetString = func(name uint32) string {
+        switch name {
+            case glconstants["GL_VERSION"]: return "OpenGL ES 3.2 Mesa 20.0.8"
+            default:                        return "OpenGL ES GLSL ES 3.20"
+        }
+    }
+    
+    type Caps struct {
+        Version      Version `glcaps:"version GL_VERSION"`
+        GLSLVersion  Version `glcaps:"version GL_SHADING_LANGUAGE_VERSION"`
+        VersionText  string  `glcaps:"version GL_VERSION"`
+        HasCompute   bool    `glcaps:"or gte version GL_VERSION 4.3 gte version GL_VERSION ES3.1"`
+        IsGL46       bool    `glcaps:"eq version GL_VERSION 4.6"`
+        IsNotGL46    bool    `glcaps:"neq version GL_VERSION 4.6"`
+        IsGLSL46     bool    `glcaps:"eq 4.6 version GL_SHADING_LANGUAGE_VERSION"`
+    }
+    
+    type test struct {
+        binding *Binding
+        expected Caps
+    }
+    
+    var tests = []test{
+        {testBinding(), Caps{
+            Version:     Version{4, 6, false, "core", "NVIDIA 535.1"},
+            GLSLVersion: Version{4, 6, false, "", "NVIDIA"},
+            VersionText: "4.6 core",
+            HasCompute:  true,
+            IsGL46:      true,
+            IsNotGL46:   false,
+            IsGLSL46:    true,
+        }},
+        {es, Caps{
+            Version:     Version{3, 2, true, "es", "Mesa 20.0.8"},
+            GLSLVersion: Version{3, 2, true, "es", ""},
+            VersionText: "ES 3.2",
+            HasCompute:  true,
+            IsGL46:      false,
+            IsNotGL46:   true,
+            IsGLSL46:    false,
+        }},
+    }
+    
+    for i, test := range tests {
+        var result Caps
+        var _, errs = Parse(test.binding, &result)
+        if len(errs) != 0 {
+            t.Errorf("Test %d: unexpected errors %v", i, errs)
+        } else if result != test.expected {
+            t.Errorf("Test %d: got %+v but wanted %+v", i, result, test.expected)
+        }
+    }
+}
+
+func TestParseVersionRequirements(t *testing.T) {
+    var target struct{
+        Version Version `glcaps:"version GL_VERSION; gte 3.3 lt 5.0 neq ES3.2 profile core required"`
+    }
+    
+    var _, errs = Parse(testBinding(), &target)
+    if len(errs) != 0 { t.Errorf("unexpected errors: %v", errs) }
+    
+    // a version from another command keeps its profile and vendor
+    var branch struct{
+        Version Version `glcaps:"if true version GL_VERSION version GL_SHADING_LANGUAGE_VERSION; profile core"`
+        Text    string  `glcaps:"if true version GL_VERSION 4.1"`
+        IsCore  bool    `glcaps:"eq if true version GL_VERSION 4.1 4.6"`
+    }
+    
+    _, errs = Parse(testBinding(), &branch)
+    if len(errs) != 0 { t.Errorf("unexpected errors: %v", errs) }
+    if (branch.Version != target.Version) || (branch.Text != "4.6 core") || !branch.IsCore {
+        t.Errorf("unexpected result %+v", branch)
+    }
+    
+    var failing struct{
+        Version Version `glcaps:"version GL_VERSION; gte 4.7"`
+    }
+    
+    _, errs = Parse(testBinding(), &failing)
+    var expected = "Version is 4.6 core but must be >= 4.7"
+    if (len(errs) != 1) || (errs[0].Message != expected) {
+        t.Errorf("got %v but wanted %q", errs, expected)
+    }
+}
+
+func TestEvalCommandNeq(t *testing.T) {
+    var command, _, err = parseCommand("neq 1 2", 0)
+    if err != nil { t.Fatalf("unexpected error: %v", err) }
+    if !evalBool(t, command, nil) { t.Errorf("unexpected result") }
+}
+
+func TestParseVectors(t *testing.T) {
+    var target struct{
+        MaxViewportDims    [2]int     `glcaps:"GetIntegerv GL_MAX_VIEWPORT_DIMS"`
+        MaxViewportDimsS   []int      `glcaps:"GetIntegerv GL_MAX_VIEWPORT_DIMS"`
+        CompressedFormats  []int      `glcaps:"GetIntegerv GL_COMPRESSED_TEXTURE_FORMATS"`
+        LineWidthRange     []float32  `glcaps:"GetFloatv GL_ALIASED_LINE_WIDTH_RANGE"`
+        WorkGroupCount     [3]int     `glcaps:"GetIntegeri_v GL_MAX_COMPUTE_WORK_GROUP_COUNT"`
+        WorkGroupCountS    []int      `glcaps:"GetIntegeri_v GL_MAX_COMPUTE_WORK_GROUP_COUNT"`
+        WorkGroupCount0    int        `glcaps:"GetIntegeri_v GL_MAX_COMPUTE_WORK_GROUP_COUNT"`
+        ViewportX          []float32  `glcaps:"GetFloati_v GL_VIEWPORT"`
+        MaxTextureSize     []int      `glcaps:"GetIntegerv GL_MAX_TEXTURE_SIZE"`
+        MaxViewportDimsU   []uint16   `glcaps:"GetIntegerv GL_MAX_VIEWPORT_DIMS"`
+        LineWidthRange64   [2]float64 `glcaps:"GetFloatv GL_ALIASED_LINE_WIDTH_RANGE"`
+    }
+    
+    var _, errs = Parse(testBinding(), &target)
+    if len(errs) != 0 { t.Fatalf("unexpected errors: %v", errs) }
+    
+    type test struct {
+        result interface{}
+        expected interface{}
+    }
+    
+    var tests = []test{
+        {target.MaxViewportDims,   [2]int{8192, 4096}},
+        {target.MaxViewportDimsS,  []int{8192, 4096}},
+        {target.CompressedFormats, []int{10, 20, 30}},
+        {target.LineWidthRange,    []float32{1.0, 7.5}},
+        {target.WorkGroupCount,    [3]int{65535, 65534, 65533}},
+        {target.WorkGroupCountS,   []int{65535, 65534, 65533}},
+        {target.WorkGroupCount0,   65535},
+        {target.ViewportX,         []float32{0, 100}},
+        {target.MaxTextureSize,    []int{4096}},
+        {target.MaxViewportDimsU,  []uint16{8192, 4096}},
+        {target.LineWidthRange64,  [2]float64{1.0, 7.5}},
+    }
+    
+    for i, test := range tests {
+        if !reflect.DeepEqual(test.result, test.expected) {
+            t.Errorf("Test %d: got %v but wanted %v", i, test.result, test.expected)
+        }
+    }
 }
 
+func TestParseVectorRequirements(t *testing.T) {
+    var target struct{
+        MaxViewportDims [2]int `glcaps:"GetIntegerv GL_MAX_VIEWPORT_DIMS; gte 8192"`
+    }
+    
+    var _, errs = Parse(testBinding(), &target)
+    if len(errs) != 1 { t.Fatalf("unexpected errors: %v", errs) }
+    
+    var expected = "MaxViewportDims[1] is 4096 but must be >= 8192"
+    if errs[0].Message != expected {
+        t.Errorf("got %q but wanted %q", errs[0].Message, expected)
+    }
+    if target.MaxViewportDims != [2]int{8192, 4096} {
+        t.Errorf("unexpected result: %v", target.MaxViewportDims)
+    }
+}
+
+func TestParseLongListBuffer(t *testing.T) {
+    const numFormats = 2 * maxResults
+    
+    // writes through an array pointer, so that "go test -race" (which enables checkptr) fails if glcaps allocates a
+    // buffer too small for every value
+    var binding = &Binding{
+        GetIntegerv: func(name uint32, data *int32) {
+            switch name {
+                case glconstants["GL_NUM_EXTENSIONS"]:
+                    *data = 0
+                case glconstants["GL_NUM_COMPRESSED_TEXTURE_FORMATS"]:
+                    *data = numFormats
+                case glconstants["GL_COMPRESSED_TEXTURE_FORMATS"]:
+                    var results = (*[numFormats]int32)(unsafe.Pointer(data))
+                    for i := range results { results[i] = int32(i + 1) }
+            }
+        },
+        GetStringi: func(name uint32, index uint32) string { return "" },
+    }
+    
+    var target struct{
+        First    int    `glcaps:"GetIntegerv GL_COMPRESSED_TEXTURE_FORMATS"`
+        FirstTwo [2]int `glcaps:"GetIntegerv GL_COMPRESSED_TEXTURE_FORMATS"`
+        All      []int  `glcaps:"GetIntegerv GL_COMPRESSED_TEXTURE_FORMATS"`
+    }
+    
+    var _, errs = Parse(binding, &target)
+    if len(errs) != 0 { t.Fatalf("unexpected errors: %v", errs) }
+    
+    if target.First != 1 { t.Errorf("got %v but wanted %v", target.First, 1) }
+    if target.FirstTwo != [2]int{1, 2} { t.Errorf("got %v but wanted %v", target.FirstTwo, [2]int{1, 2}) }
+    if (len(target.All) != numFormats) || (target.All[numFormats - 1] != numFormats) {
+        t.Errorf("unexpected result: %v", target.All)
+    }
+}
 
 /*
 func TestParseTagCommand4(t *testing.T) {
@@ -0,0 +1,98 @@
+package glcaps
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// Version is a parsed OpenGL, OpenGL ES, or GLSL version string, such as the result of GetString GL_VERSION.
+//
+// A struct field of type Version can be filled by the version command. See Parse.
+type Version struct {
+    Major   int
+    Minor   int
+    ES      bool   // true for OpenGL ES or GLSL ES
+    Profile string // "core", "compatibility", "es", or empty if unknown
+    Vendor  string // any vendor-specific information after the version number e.g. "NVIDIA 535.1"
+}
+
+// ParseVersion parses a version string returned by OpenGL for GL_VERSION or GL_SHADING_LANGUAGE_VERSION. For
+// example, "4.6.0 NVIDIA 535.1", "3.3 (Core Profile) Mesa 20.0.8", "OpenGL ES 3.2 Mesa 20.0.8", "4.60 NVIDIA" or
+// "OpenGL ES GLSL ES 3.20".
+//
+// It also accepts a short form "major.minor" for OpenGL, optionally followed by a profile, or "ESmajor.minor" for
+// OpenGL ES, such as "4.3", "4.6 core" or "ES3.1". So the result of Version.String can be parsed again.
+//
+// A GLSL version like "4.60" is read as 4.6, so that it compares equal to the matching OpenGL version. The release
+// number, if any, is ignored. If the string cannot be parsed, the returned error wraps ErrSyntax.
+func ParseVersion(s string) (Version, error) {
+    var v Version
+    var rest = strings.TrimSpace(s)
+
+    for _, prefix := range []string{"OpenGL ES GLSL ES ", "OpenGL ES-CM ", "OpenGL ES-CL ", "OpenGL ES ", "ES", "es"} {
+        if strings.HasPrefix(rest, prefix) {
+            v.ES = true
+            v.Profile = "es"
+            rest = strings.TrimSpace(rest[len(prefix):])
+            break
+        }
+    }
+
+    // major.minor[.release], up to the first space
+    var number = rest
+    if i := strings.IndexByte(rest, ' '); i >= 0 {
+        number, rest = rest[0:i], strings.TrimSpace(rest[i+1:])
+    } else {
+        rest = ""
+    }
+
+    var parts = strings.SplitN(number, ".", 3)
+    if len(parts) < 2 { return Version{}, fail(ErrSyntax, "not a version: '%s'", s) }
+
+    var major, majorErr = strconv.ParseUint(parts[0], 10, 16)
+    var minor, minorErr = strconv.ParseUint(parts[1], 10, 16)
+    if (majorErr != nil) || (minorErr != nil) { return Version{}, fail(ErrSyntax, "not a version: '%s'", s) }
+
+    // GLSL 4.60 is OpenGL 4.6
+    if (len(parts[1]) == 2) && (parts[1][1] == '0') { minor /= 10 }
+
+    v.Major, v.Minor = int(major), int(minor)
+
+    for _, p := range []struct{profile, text string}{
+        {"core",          "(Core Profile)"},
+        {"compatibility", "(Compatibility Profile)"},
+    } {
+        if i := strings.Index(strings.ToLower(rest), strings.ToLower(p.text)); i >= 0 {
+            v.Profile = p.profile
+            rest = strings.TrimSpace(rest[0:i] + rest[i+len(p.text):])
+        }
+    }
+
+    // the short form from Version.String
+    if !v.ES && ((rest == "core") || (rest == "compatibility")) {
+        v.Profile, rest = rest, ""
+    }
+
+    v.Vendor = rest
+    return v, nil
+}
+
+// String returns a version in a short form such as "4.6", "4.6 core" or "ES 3.2". Vendor information is omitted.
+func (v Version) String() string {
+    if v.ES { return fmt.Sprintf("ES %d.%d", v.Major, v.Minor) }
+    if len(v.Profile) > 0 { return fmt.Sprintf("%d.%d %s", v.Major, v.Minor, v.Profile) }
+    return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// compare returns -1, 0 or 1 if v is less than, equal to, or greater than w, ignoring everything except the major
+// and minor version.
+func (v Version) compare(w Version) int {
+    switch {
+        case v.Major < w.Major: return -1
+        case v.Major > w.Major: return  1
+        case v.Minor < w.Minor: return -1
+        case v.Minor > w.Minor: return  1
+        default:                return  0
+    }
+}